@@ -26,10 +26,12 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/fengxuway/wire/internal/wire"
 	"github.com/google/subcommands"
@@ -43,8 +45,12 @@ func main() {
 	subcommands.Register(subcommands.HelpCommand(), "")
 	subcommands.Register(&checkCmd{}, "")
 	subcommands.Register(&diffCmd{}, "")
+	subcommands.Register(&docCmd{}, "")
 	subcommands.Register(&genCmd{}, "")
+	subcommands.Register(&graphCmd{}, "")
+	subcommands.Register(&metricsCmd{}, "")
 	subcommands.Register(&showCmd{}, "")
+	subcommands.Register(&solveCmd{}, "")
 	flag.Parse()
 
 	// Initialize the default logger to log to stderr.
@@ -62,8 +68,12 @@ func main() {
 		"flags":    true, // builtin
 		"check":    true,
 		"diff":     true,
+		"doc":      true,
 		"gen":      true,
+		"graph":    true,
+		"metrics":  true,
 		"show":     true,
+		"solve":    true,
 	}
 	// Default to running the "gen" command.
 	if args := flag.Args(); len(args) == 0 || !allCmds[args[0]] {
@@ -83,9 +93,36 @@ func packages(f *flag.FlagSet) []string {
 	return pkgs
 }
 
+// generate runs wire.Generate for the packages listed in f. An argument that
+// is the absolute path of a directory is generated with wire.GenerateDir
+// instead, so that it may be outside of the module containing wd.
+func generate(ctx context.Context, wd string, f *flag.FlagSet, opts *wire.GenerateOptions) ([]wire.GenerateResult, []error) {
+	var outs []wire.GenerateResult
+	var errs []error
+	var patterns []string
+	for _, p := range packages(f) {
+		if fi, err := os.Stat(p); err != nil || !fi.IsDir() || !filepath.IsAbs(p) {
+			patterns = append(patterns, p)
+			continue
+		}
+		out, dirErrs := wire.GenerateDir(ctx, p, os.Environ(), opts)
+		if len(dirErrs) > 0 {
+			errs = append(errs, dirErrs...)
+			continue
+		}
+		outs = append(outs, out)
+	}
+	if len(patterns) > 0 {
+		patternOuts, patternErrs := wire.Generate(ctx, wd, os.Environ(), patterns, opts)
+		outs = append(outs, patternOuts...)
+		errs = append(errs, patternErrs...)
+	}
+	return outs, errs
+}
+
 // newGenerateOptions returns an initialized wire.GenerateOptions, possibly
 // with the Header option set.
-func newGenerateOptions(headerFile string) (*wire.GenerateOptions, error) {
+func newGenerateOptions(headerFile, configFile string) (*wire.GenerateOptions, error) {
 	opts := new(wire.GenerateOptions)
 	if headerFile != "" {
 		var err error
@@ -94,13 +131,146 @@ func newGenerateOptions(headerFile string) (*wire.GenerateOptions, error) {
 			return nil, fmt.Errorf("failed to read header file %q: %v", headerFile, err)
 		}
 	}
+	if configFile != "" {
+		data, err := ioutil.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %q: %v", configFile, err)
+		}
+		opts.InjectorSets, err = wire.ParseConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", configFile, err)
+		}
+	}
 	return opts, nil
 }
 
+// generateFlags holds the flags that set wire.GenerateOptions, shared by
+// the commands that generate injectors.
+type generateFlags struct {
+	headerFile          string
+	tags                string
+	convertBasic        bool
+	assertBindings      bool
+	generatorName       string
+	suggestProviders    bool
+	requireDocs         bool
+	configFile          string
+	warnUnusedArgs      bool
+	warnBuiltinTypes    bool
+	rejectUnusedArgs    bool
+	strictBindings      bool
+	ambientContext      bool
+	outputPackage       string
+	injectTag           string
+	planComments        bool
+	sourceComments      bool
+	minimizeLiveVars    bool
+	givenFields         bool
+	autoAddress         bool
+	assignableGivens    bool
+	errorVarPerProvider bool
+	cleanupOnError      bool
+	wrapErrors          bool
+	errorLabels         bool
+	autoBind            bool
+	autoDeref           bool
+	structOutput        bool
+	overrides           overrideFlag
+}
+
+func (gf *generateFlags) register(f *flag.FlagSet) {
+	f.StringVar(&gf.headerFile, "header_file", "", "path to file to insert as a header in wire_gen.go")
+	f.StringVar(&gf.tags, "tags", "", "append build tags to the default wirebuild")
+	f.BoolVar(&gf.convertBasic, "convert_basic", false, "satisfy defined basic types by conversion from their underlying type and vice versa")
+	f.BoolVar(&gf.assertBindings, "assert_bindings", false, "emit compile-time assertions for the interface bindings used by injectors")
+	f.StringVar(&gf.generatorName, "generator_name", "", "tool name to use in the \"Code generated\" comment of wire_gen.go (default \"Wire\")")
+	f.BoolVar(&gf.suggestProviders, "suggest_providers", false, "name functions that return a type in errors for types without a provider")
+	f.BoolVar(&gf.requireDocs, "require_provider_docs", false, "warn about provider functions used by injectors that have no doc comment")
+	f.StringVar(&gf.configFile, "config", "", "path to a JSON file listing provider sets to add to injectors")
+	f.BoolVar(&gf.warnUnusedArgs, "warn_unused_args", false, "warn about injector arguments that are not needed to produce the output")
+	f.BoolVar(&gf.warnBuiltinTypes, "warn_builtin_types", false, "warn about providers of types like string or int that other providers take")
+	f.BoolVar(&gf.rejectUnusedArgs, "reject_unused_args", false, "report an error for injector arguments that are not needed to produce the output")
+	f.BoolVar(&gf.strictBindings, "strict_bindings", false, "report an error when a type has both an interface binding and a provider, instead of using the provider")
+	f.BoolVar(&gf.ambientContext, "ambient_context", false, "hint at a ctx parameter when nothing provides a context.Context")
+	f.StringVar(&gf.outputPackage, "output_package", "", "import path of a package to generate the injectors into instead of the package declaring them")
+	f.StringVar(&gf.injectTag, "inject_tag", "", "build tag of the files declaring injectors (default \"wireinject\")")
+	f.BoolVar(&gf.planComments, "plan_comments", false, "list the steps of each injector in a comment above it")
+	f.BoolVar(&gf.sourceComments, "source_comments", false, "add the position of the provider before each of its calls in a comment")
+	f.BoolVar(&gf.minimizeLiveVars, "minimize_live_vars", false, "reorder provider calls so that injectors hold fewer values in variables at once")
+	f.BoolVar(&gf.givenFields, "given_fields", false, "satisfy dependencies with exported fields of struct injector arguments")
+	f.BoolVar(&gf.autoAddress, "auto_address", false, "satisfy a pointer type without a provider by taking the address of a provided value")
+	f.BoolVar(&gf.assignableGivens, "assignable_givens", false, "satisfy an interface without a provider with the injector argument that implements it")
+	f.BoolVar(&gf.errorVarPerProvider, "error_var_per_provider", false, "give each provider's error its own variable named after the provider")
+	f.BoolVar(&gf.cleanupOnError, "cleanup_on_error", false, "return a cleanup function for the values already built along with an error")
+	f.BoolVar(&gf.wrapErrors, "wrap_errors", false, "wrap each provider error with the name of the provider")
+	f.BoolVar(&gf.errorLabels, "error_labels", false, "run cleanups and return errors from one labeled section at the end of each injector")
+	f.BoolVar(&gf.autoBind, "auto_bind", false, "bind an interface without a provider to the one provided type that implements it")
+	f.BoolVar(&gf.autoDeref, "auto_deref", false, "satisfy a type without a provider by dereferencing a provided pointer to it")
+	f.BoolVar(&gf.structOutput, "struct_output", false, "build a struct injector output without a provider from its exported fields")
+	f.Var(&gf.overrides, "override", "provider function, as importpath.FuncName, that replaces the other providers of its types in every injector; may be repeated")
+}
+
+// options returns the wire.GenerateOptions that the flags describe.
+func (gf *generateFlags) options() (*wire.GenerateOptions, error) {
+	opts, err := newGenerateOptions(gf.headerFile, gf.configFile)
+	if err != nil {
+		return nil, err
+	}
+	opts.Tags = gf.tags
+	opts.ConvertBasic = gf.convertBasic
+	opts.AssertBindings = gf.assertBindings
+	opts.GeneratorName = gf.generatorName
+	opts.SuggestProviders = gf.suggestProviders
+	opts.RequireProviderDocs = gf.requireDocs
+	opts.WarnUnusedArgs = gf.warnUnusedArgs
+	opts.WarnBuiltinTypes = gf.warnBuiltinTypes
+	opts.RejectUnusedArgs = gf.rejectUnusedArgs
+	opts.StrictBindings = gf.strictBindings
+	opts.AmbientContext = gf.ambientContext
+	opts.OutputPackage = gf.outputPackage
+	opts.InjectTag = gf.injectTag
+	opts.PlanComments = gf.planComments
+	opts.SourceComments = gf.sourceComments
+	opts.MinimizeLiveVars = gf.minimizeLiveVars
+	opts.GivenFields = gf.givenFields
+	opts.AutoAddress = gf.autoAddress
+	opts.AssignableGivens = gf.assignableGivens
+	opts.ErrorVarPerProvider = gf.errorVarPerProvider
+	opts.CleanupOnError = gf.cleanupOnError
+	opts.WrapErrors = gf.wrapErrors
+	opts.ErrorLabels = gf.errorLabels
+	opts.AutoBind = gf.autoBind
+	opts.AutoDeref = gf.autoDeref
+	opts.StructOutput = gf.structOutput
+	opts.Overrides = gf.overrides
+	return opts, nil
+}
+
+// overrideFlag is a flag.Value that collects the provider functions named
+// by each use of the -override flag.
+type overrideFlag []wire.ProviderOverride
+
+func (o *overrideFlag) String() string {
+	names := make([]string, len(*o))
+	for i, po := range *o {
+		names[i] = po.ImportPath + "." + po.FuncName
+	}
+	return strings.Join(names, ",")
+}
+
+func (o *overrideFlag) Set(s string) error {
+	i := strings.LastIndex(s, ".")
+	if i <= strings.LastIndex(s, "/") || i == len(s)-1 {
+		return fmt.Errorf("%q is not of the form importpath.FuncName", s)
+	}
+	*o = append(*o, wire.ProviderOverride{ImportPath: s[:i], FuncName: s[i+1:]})
+	return nil
+}
+
 type genCmd struct {
-	headerFile     string
+	generateFlags
 	prefixFileName string
-	tags           string
+	readOnly       bool
 }
 
 func (*genCmd) Name() string { return "gen" }
@@ -112,13 +282,15 @@ func (*genCmd) Usage() string {
 
   Given one or more packages, gen creates the wire_gen.go file for each.
 
-  If no packages are listed, it defaults to ".".
+  If no packages are listed, it defaults to ".". A package may also be given
+  as the absolute path of its directory, which may be outside of the current
+  module, so that editors can run gen without knowing the import path.
 `
 }
 func (cmd *genCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.headerFile, "header_file", "", "path to file to insert as a header in wire_gen.go")
+	cmd.generateFlags.register(f)
 	f.StringVar(&cmd.prefixFileName, "output_file_prefix", "", "string to prepend to output file names.")
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	f.BoolVar(&cmd.readOnly, "read_only", false, "write wire_gen.go without write permission to discourage editing it")
 }
 
 func (cmd *genCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
@@ -127,16 +299,15 @@ func (cmd *genCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interfa
 		log.Println("failed to get working directory: ", err)
 		return subcommands.ExitFailure
 	}
-	opts, err := newGenerateOptions(cmd.headerFile)
+	opts, err := cmd.options()
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
-
 	opts.PrefixOutputFile = cmd.prefixFileName
-	opts.Tags = cmd.tags
+	opts.ReadOnly = cmd.readOnly
 
-	outs, errs := wire.Generate(ctx, wd, os.Environ(), packages(f), opts)
+	outs, errs := generate(ctx, wd, f, opts)
 	if len(errs) > 0 {
 		logErrors(errs)
 		log.Println("generate failed")
@@ -147,13 +318,19 @@ func (cmd *genCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interfa
 	}
 	success := true
 	for _, out := range outs {
+		logErrors(out.Warnings)
 		if len(out.Errs) > 0 {
 			logErrors(out.Errs)
 			log.Printf("%s: generate failed\n", out.PkgPath)
 			success = false
 		}
 		if len(out.Content) == 0 {
-			// No Wire output. Maybe errors, maybe no Wire directives.
+			// No Wire output. Maybe errors, maybe no Wire directives, in
+			// which case Commit may remove the output of an earlier run.
+			if err := out.Commit(); err != nil {
+				log.Printf("%s: failed to remove %s: %v\n", out.PkgPath, out.OutputPath, err)
+				success = false
+			}
 			continue
 		}
 		if err := out.Commit(); err == nil {
@@ -171,8 +348,7 @@ func (cmd *genCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interfa
 }
 
 type diffCmd struct {
-	headerFile string
-	tags       string
+	generateFlags
 }
 
 func (*diffCmd) Name() string { return "diff" }
@@ -185,15 +361,15 @@ func (*diffCmd) Usage() string {
   Given one or more packages, diff generates the content for their wire_gen.go
   files and outputs the diff against the existing files.
 
-  If no packages are listed, it defaults to ".".
+  If no packages are listed, it defaults to ".". As with gen, a package may
+  also be given as the absolute path of its directory.
 
   Similar to the diff command, it returns 0 if no diff, 1 if different, 2
   plus an error if trouble.
 `
 }
 func (cmd *diffCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.headerFile, "header_file", "", "path to file to insert as a header in wire_gen.go")
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	cmd.generateFlags.register(f)
 }
 func (cmd *diffCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
 	const (
@@ -205,15 +381,13 @@ func (cmd *diffCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 		log.Println("failed to get working directory: ", err)
 		return errReturn
 	}
-	opts, err := newGenerateOptions(cmd.headerFile)
+	opts, err := cmd.options()
 	if err != nil {
 		log.Println(err)
 		return subcommands.ExitFailure
 	}
 
-	opts.Tags = cmd.tags
-
-	outs, errs := wire.Generate(ctx, wd, os.Environ(), packages(f), opts)
+	outs, errs := generate(ctx, wd, f, opts)
 	if len(errs) > 0 {
 		logErrors(errs)
 		log.Println("generate failed")
@@ -225,6 +399,7 @@ func (cmd *diffCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 	success := true
 	hadDiff := false
 	for _, out := range outs {
+		logErrors(out.Warnings)
 		if len(out.Errs) > 0 {
 			logErrors(out.Errs)
 			log.Printf("%s: generate failed\n", out.PkgPath)
@@ -261,7 +436,8 @@ func (cmd *diffCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 }
 
 type showCmd struct {
-	tags string
+	tags      string
+	injectTag string
 }
 
 func (*showCmd) Name() string { return "show" }
@@ -281,6 +457,7 @@ func (*showCmd) Usage() string {
 }
 func (cmd *showCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	f.StringVar(&cmd.injectTag, "inject_tag", "", "build tag of the files declaring injectors (default \"wireinject\")")
 }
 func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
 	wd, err := os.Getwd()
@@ -288,7 +465,7 @@ func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 		log.Println("failed to get working directory: ", err)
 		return subcommands.ExitFailure
 	}
-	info, errs := wire.Load(ctx, wd, os.Environ(), cmd.tags, packages(f))
+	info, errs := wire.Load(ctx, wd, os.Environ(), cmd.injectTag, cmd.tags, packages(f))
 	if info != nil {
 		keys := make([]wire.ProviderSetID, 0, len(info.Sets))
 		for k := range info.Sets {
@@ -320,6 +497,8 @@ func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 						out[types.TypeString(t, nil)] = v.Pos
 					case *wire.Field:
 						out[types.TypeString(t, nil)] = v.Pos
+					case *wire.Collection:
+						out[types.TypeString(t, nil)] = v.Pos
 					default:
 						panic("unreachable")
 					}
@@ -352,8 +531,206 @@ func (cmd *showCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interf
 	return subcommands.ExitSuccess
 }
 
+type docCmd struct {
+	tags      string
+	injectTag string
+}
+
+func (*docCmd) Name() string { return "doc" }
+func (*docCmd) Synopsis() string {
+	return "describe the providers offered by each top-level provider set"
+}
+func (*docCmd) Usage() string {
+	return `doc [packages]
+
+  Given one or more packages, doc finds all the provider sets declared as
+  top-level variables and prints the providers each of them offers, including
+  those of the sets it imports, with their signatures and doc comments.
+
+  If no packages are listed, it defaults to ".".
+`
+}
+func (cmd *docCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	f.StringVar(&cmd.injectTag, "inject_tag", "", "build tag of the files declaring injectors (default \"wireinject\")")
+}
+func (cmd *docCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	docs, errs := wire.DocumentSets(ctx, wd, os.Environ(), cmd.injectTag, cmd.tags, packages(f))
+	for i, sd := range docs {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(sd.ID)
+		for _, p := range sd.Providers {
+			fmt.Printf("\t%s.%s\n", p.ImportPath, p.Name)
+			if p.Signature != "" {
+				fmt.Printf("\t\t%s\n", p.Signature)
+			}
+			fmt.Printf("\t\tat %v\n", p.Pos)
+			for _, line := range strings.Split(strings.TrimSuffix(p.Doc, "\n"), "\n") {
+				if line != "" {
+					fmt.Printf("\t\t%s\n", line)
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("error loading packages")
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+type graphCmd struct {
+	tags      string
+	injectTag string
+}
+
+func (*graphCmd) Name() string { return "graph" }
+func (*graphCmd) Synopsis() string {
+	return "print the injectors' dependency graphs in DOT format"
+}
+func (*graphCmd) Usage() string {
+	return `graph [packages]
+
+  Given one or more packages, graph finds all the injector functions and
+  prints a Graphviz DOT document showing which providers each injector calls
+  and which values they receive.
+
+  If no packages are listed, it defaults to ".".
+`
+}
+func (cmd *graphCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	f.StringVar(&cmd.injectTag, "inject_tag", "", "build tag of the files declaring injectors (default \"wireinject\")")
+}
+func (cmd *graphCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	dot, errs := wire.Graph(ctx, wd, os.Environ(), cmd.injectTag, cmd.tags, packages(f))
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("error loading packages")
+		return subcommands.ExitFailure
+	}
+	os.Stdout.Write(dot)
+	return subcommands.ExitSuccess
+}
+
+type metricsCmd struct {
+	tags      string
+	injectTag string
+}
+
+func (*metricsCmd) Name() string { return "metrics" }
+func (*metricsCmd) Synopsis() string {
+	return "print complexity metrics for each injector"
+}
+func (*metricsCmd) Usage() string {
+	return `metrics [packages]
+
+  Given one or more packages, metrics prints a table with a row for each
+  injector function: the number of providers it calls, the length of its
+  longest chain of dependencies, the number of packages declaring those
+  providers, and whether it can fail or has a cleanup function.
+
+  If no packages are listed, it defaults to ".".
+`
+}
+func (cmd *metricsCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	f.StringVar(&cmd.injectTag, "inject_tag", "", "build tag of the files declaring injectors (default \"wireinject\")")
+}
+func (cmd *metricsCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	metrics, errs := wire.Metrics(ctx, wd, os.Environ(), cmd.injectTag, cmd.tags, packages(f))
+	if len(metrics) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "INJECTOR\tPROVIDERS\tDEPTH\tPACKAGES\tCAN FAIL\tCLEANUP")
+		for _, m := range metrics {
+			fmt.Fprintf(w, "%s.%s\t%d\t%d\t%d\t%t\t%t\n", m.ImportPath, m.FuncName, m.Providers, m.MaxDepth, m.Packages, m.CanFail, m.HasCleanup)
+		}
+		w.Flush()
+	}
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("error loading packages")
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+type solveCmd struct {
+	tags      string
+	injectTag string
+}
+
+func (*solveCmd) Name() string { return "solve" }
+func (*solveCmd) Synopsis() string {
+	return "print the steps that provider sets take to build a type"
+}
+func (*solveCmd) Usage() string {
+	return `solve package type set...
+
+  Given a package, a type and one or more provider sets, solve prints the
+  steps that an injector without arguments would take to build the type from
+  the sets, in order, without generating any code. Each step lists the steps
+  whose values it takes.
+
+  Types and sets are written with their import paths, as in
+  "*example.com/foo.Bar" and "example.com/foo.Set". A set name without an
+  import path refers to a set in the package.
+`
+}
+func (cmd *solveCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	f.StringVar(&cmd.injectTag, "inject_tag", "", "build tag of the files declaring injectors (default \"wireinject\")")
+}
+func (cmd *solveCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
+	if f.NArg() < 3 {
+		log.Println("solve needs a package, a type and at least one provider set")
+		return subcommands.ExitUsageError
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Println("failed to get working directory: ", err)
+		return subcommands.ExitFailure
+	}
+	steps, errs := wire.Solve(ctx, wd, os.Environ(), cmd.injectTag, cmd.tags, f.Arg(0), f.Args()[2:], f.Arg(1))
+	if len(errs) > 0 {
+		logErrors(errs)
+		log.Println("solve failed")
+		return subcommands.ExitFailure
+	}
+	for i, step := range steps {
+		fmt.Printf("%d: %s -> %s", i, step.Provider, step.Out)
+		if len(step.Args) > 0 {
+			args := make([]string, len(step.Args))
+			for j, a := range step.Args {
+				args[j] = strconv.Itoa(a)
+			}
+			fmt.Printf(", from %s", strings.Join(args, ", "))
+		}
+		fmt.Println()
+	}
+	return subcommands.ExitSuccess
+}
+
 type checkCmd struct {
-	tags string
+	generateFlags
 }
 
 func (*checkCmd) Name() string { return "check" }
@@ -364,13 +741,14 @@ func (*checkCmd) Usage() string {
 	return `check [-tags tag,list] [packages]
 
   Given one or more packages, check prints any type-checking or Wire errors
-  found with top-level variable provider sets or injector functions.
+  that gen would report for their injector functions and provider sets,
+  without generating any files. It takes the same options as gen.
 
   If no packages are listed, it defaults to ".".
 `
 }
 func (cmd *checkCmd) SetFlags(f *flag.FlagSet) {
-	f.StringVar(&cmd.tags, "tags", "", "append build tags to the default wirebuild")
+	cmd.generateFlags.register(f)
 }
 func (cmd *checkCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...interface{}) subcommands.ExitStatus {
 	wd, err := os.Getwd()
@@ -378,10 +756,14 @@ func (cmd *checkCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...inter
 		log.Println("failed to get working directory: ", err)
 		return subcommands.ExitFailure
 	}
-	_, errs := wire.Load(ctx, wd, os.Environ(), cmd.tags, packages(f))
-	if len(errs) > 0 {
+	opts, err := cmd.options()
+	if err != nil {
+		log.Println(err)
+		return subcommands.ExitFailure
+	}
+	if errs := wire.Check(ctx, wd, os.Environ(), packages(f), opts); len(errs) > 0 {
 		logErrors(errs)
-		log.Println("error loading packages")
+		log.Println("check failed")
 		return subcommands.ExitFailure
 	}
 	return subcommands.ExitSuccess
@@ -390,7 +772,7 @@ func (cmd *checkCmd) Execute(ctx context.Context, f *flag.FlagSet, args ...inter
 type outGroup struct {
 	name    string
 	inputs  *typeutil.Map // values are not important
-	outputs *typeutil.Map // values are *wire.Provider, *wire.Value, or *wire.Field
+	outputs *typeutil.Map // values are *wire.Provider, *wire.Value, *wire.Field, or *wire.Collection
 }
 
 // gather flattens a provider set into outputs grouped by the inputs
@@ -415,6 +797,7 @@ func gather(info *wire.Info, key wire.ProviderSetID) (_ []outGroup, imports map[
 			imports[formatProviderSetName(curr.PkgPath, curr.VarName)] = struct{}{}
 		}
 		next = append(next, curr.Imports...)
+		next = append(next, curr.Fallbacks...)
 	}
 
 	// Depth-first search to build groups.
@@ -443,20 +826,36 @@ func gather(info *wire.Info, key wire.ProviderSetID) (_ []outGroup, imports map[
 			case pv.IsArg():
 				// This is an injector argument.
 				inputVisited.Set(curr, -1)
-			case pv.IsProvider():
+			case pv.IsProvider() || pv.IsCollection():
 				// Try to see if any args haven't been visited.
-				p := pv.Provider()
+				var output interface{}
+				var args []types.Type
+				if pv.IsProvider() {
+					p := pv.Provider()
+					output = p
+					for _, arg := range p.Args {
+						args = append(args, arg.Type)
+					}
+				} else {
+					c := pv.Collection()
+					output = c
+					for _, p := range c.Providers {
+						for _, arg := range p.Args {
+							args = append(args, arg.Type)
+						}
+					}
+				}
 				allPresent := true
-				for _, arg := range p.Args {
-					if inputVisited.At(arg.Type) == nil {
+				for _, arg := range args {
+					if inputVisited.At(arg) == nil {
 						allPresent = false
 					}
 				}
 				if !allPresent {
 					stk = append(stk, curr)
-					for _, arg := range p.Args {
-						if inputVisited.At(arg.Type) == nil {
-							stk = append(stk, arg.Type)
+					for _, arg := range args {
+						if inputVisited.At(arg) == nil {
+							stk = append(stk, arg)
 						}
 					}
 					continue dfs
@@ -465,24 +864,24 @@ func gather(info *wire.Info, key wire.ProviderSetID) (_ []outGroup, imports map[
 				// Build up set of input types, match to a group.
 				in := new(typeutil.Map)
 				in.SetHasher(hash)
-				for _, arg := range p.Args {
-					i := inputVisited.At(arg.Type).(int)
+				for _, arg := range args {
+					i := inputVisited.At(arg).(int)
 					if i == -1 {
-						in.Set(arg.Type, true)
+						in.Set(arg, true)
 					} else {
 						mergeTypeSets(in, groups[i].inputs)
 					}
 				}
 				for i := range groups {
 					if sameTypeKeys(groups[i].inputs, in) {
-						groups[i].outputs.Set(curr, p)
+						groups[i].outputs.Set(curr, output)
 						inputVisited.Set(curr, i)
 						continue dfs
 					}
 				}
 				out := new(typeutil.Map)
 				out.SetHasher(hash)
-				out.Set(curr, p)
+				out.Set(curr, output)
 				inputVisited.Set(curr, len(groups))
 				groups = append(groups, outGroup{
 					inputs:  in,
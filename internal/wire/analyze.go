@@ -33,6 +33,12 @@ const (
 	structProvider
 	valueExpr
 	selectorExpr
+	convertExpr
+	addressExpr
+	collectExpr
+	resultExpr
+	derefExpr
+	invokeCall
 )
 
 // A call represents a step of an injector function.  It may be either a
@@ -42,16 +48,22 @@ type call struct {
 	// kind indicates the code pattern to use.
 	kind callKind
 
-	// out is the type this step produces.
+	// out is the type this step produces. It is nil for
+	// kind == invokeCall, which produces nothing.
 	out types.Type
 
 	// pkg and name identify one of the following:
 	// 1) the provider to call for kind == funcProviderCall;
 	// 2) the type to construct for kind == structProvider;
-	// 3) the name to select for kind == selectorExpr.
+	// 3) the name to select for kind == selectorExpr;
+	// 4) the function to call for kind == invokeCall.
 	pkg  *types.Package
 	name string
 
+	// pos is the position of the provider for kind == funcProviderCall and
+	// kind == structProvider. It is not set for calls to shared values.
+	pos token.Pos
+
 	// args is a list of arguments to call the provider with. Each element is:
 	// a) one of the givens (args[i] < len(given)),
 	// b) the result of a previous provider call (args[i] >= len(given))
@@ -60,6 +72,20 @@ type call struct {
 	//
 	// If kind == selectorExpr, then the length of this slice will be 1 and the
 	// "argument" will be the value to access fields from.
+	//
+	// If kind == convertExpr, then the length of this slice will be 1 and the
+	// "argument" will be the value to convert to out.
+	//
+	// If kind == addressExpr, then the length of this slice will be 1 and the
+	// "argument" will be the value to take the address of.
+	//
+	// If kind == collectExpr, then these are the elements of the slice.
+	//
+	// If kind == resultExpr, then the length of this slice will be 1 and the
+	// "argument" will be the provider call whose result to use.
+	//
+	// If kind == derefExpr, then the length of this slice will be 1 and the
+	// "argument" will be the pointer to dereference.
 	args []int
 
 	// varargs is true if the provider function is variadic.
@@ -73,12 +99,32 @@ type call struct {
 	// This will be nil for kind == valueExpr.
 	ins []types.Type
 
-	// The following are only set for kind == funcProviderCall:
+	// The following are only set for kind == funcProviderCall, except for
+	// hasErr, which is also set for kind == invokeCall:
 
 	// hasCleanup is true if the provider call returns a cleanup function.
 	hasCleanup bool
 	// hasErr is true if the provider call returns an error.
 	hasErr bool
+	// errType is the type of the error if it is not error itself.
+	errType types.Type
+	// typeArgs is the list of type arguments to instantiate a generic
+	// provider with.
+	typeArgs []types.Type
+	// method is true if the provider is a method, which is called on the
+	// first argument.
+	method bool
+	// shared is true if the call returns a value declared with wire.Shared,
+	// whose errors are already wrapped when WrapErrors is set.
+	shared bool
+	// outs is the list of values returned by a provider that returns more
+	// than one. out is then the first of them.
+	outs []types.Type
+
+	// The following are only set for kind == resultExpr:
+
+	// result is the index of the provider result to use.
+	result int
 
 	// The following are only set for kind == valueExpr:
 
@@ -90,9 +136,54 @@ type call struct {
 	ptrToField bool
 }
 
+// solveOptions holds the optional matching rules for solve. The zero value
+// matches types by identity only.
+type solveOptions struct {
+	// convertBasic allows a defined type whose underlying type is a basic
+	// type to be produced from its underlying type and vice versa.
+	convertBasic bool
+	// givenFields allows a type to be produced from an exported field of
+	// an injector argument of struct type, as long as exactly one such
+	// field has the type.
+	givenFields bool
+	// autoAddress allows a pointer type *T to be produced by taking the
+	// address of a T.
+	autoAddress bool
+	// autoDeref allows a type T to be produced by dereferencing a *T.
+	autoDeref bool
+	// assignableGivens allows an interface type to be satisfied by the one
+	// injector argument whose type implements it.
+	assignableGivens bool
+	// autoBind allows an interface type to be satisfied by the one
+	// non-interface type in the provider set that implements it.
+	autoBind bool
+	// structOutput allows the output of the injector, if it is a named
+	// struct or a pointer to one, to be built by filling in its exported
+	// fields.
+	structOutput bool
+	// suggest, if not nil, returns a hint to add to the error for a type
+	// that has no provider, or the empty string if it has none.
+	suggest func(types.Type) string
+}
+
+// suggestion returns the hint for a type that has no provider, starting
+// with a newline, or the empty string if there is none.
+func (opts *solveOptions) suggestion(t types.Type) string {
+	if opts.suggest == nil {
+		return ""
+	}
+	if s := opts.suggest(t); s != "" {
+		return "\n" + s
+	}
+	return ""
+}
+
 // solve finds the sequence of calls required to produce an output type
-// with an optional set of provided inputs.
-func solve(fset *token.FileSet, out types.Type, given *types.Tuple, set *ProviderSet) ([]call, []error) {
+// with an optional set of provided inputs. opts may be nil.
+func solve(fset *token.FileSet, out types.Type, given *types.Tuple, set *ProviderSet, opts *solveOptions) ([]call, []error) {
+	if opts == nil {
+		opts = new(solveOptions)
+	}
 	ec := new(errorCollector)
 
 	// Start building the mapping of type to local variable of the given type.
@@ -109,38 +200,206 @@ func solve(fset *token.FileSet, out types.Type, given *types.Tuple, set *Provide
 	errAbort := errors.New("failed to visit")
 	var used []*providerSetSrc
 	var calls []call
+	// tupleCalls maps a provider that returns several values to the index
+	// of its call.
+	tupleCalls := make(map[*Provider]int)
 	type frame struct {
 		t    types.Type
 		from types.Type
 		up   *frame
+		// derived is true if t is produced from another type by a
+		// conversion or by taking its address.
+		derived bool
+		// inv is the function to call for a frame that stands for a
+		// wire.Invoke, whose t is nil.
+		inv *Invocation
 	}
+	// The invoked functions are called before the output is built, in the
+	// order they are listed.
 	stk := []frame{{t: out}}
+	for i := len(set.Invocations) - 1; i >= 0; i-- {
+		stk = append(stk, frame{inv: set.Invocations[i]})
+	}
 dfs:
 	for len(stk) > 0 {
 		curr := stk[len(stk)-1]
 		stk = stk[:len(stk)-1]
+		if inv := curr.inv; inv != nil {
+			// As for a provider, visit the arguments first.
+			visitedArgs := true
+			for i := len(inv.Args) - 1; i >= 0; i-- {
+				if index.At(inv.Args[i].Type) == nil {
+					if visitedArgs {
+						stk = append(stk, curr)
+						visitedArgs = false
+					}
+					stk = append(stk, frame{t: inv.Args[i].Type, up: &curr})
+				}
+			}
+			if !visitedArgs {
+				continue
+			}
+			c := call{
+				kind:    invokeCall,
+				pkg:     inv.Pkg,
+				name:    inv.Name,
+				pos:     inv.Pos,
+				varargs: inv.Varargs,
+				hasErr:  inv.HasErr,
+			}
+			for _, a := range inv.Args {
+				v := index.At(a.Type)
+				if v == errAbort {
+					continue dfs
+				}
+				c.args = append(c.args, v.(int))
+				c.ins = append(c.ins, a.Type)
+			}
+			calls = append(calls, c)
+			continue
+		}
 		if index.At(curr.t) != nil {
 			continue
 		}
 
 		pv := set.For(curr.t)
 		if pv.IsNil() {
-			if curr.from == nil {
-				ec.add(fmt.Errorf("no provider found for %s, output of injector", types.TypeString(curr.t, nil)))
+			var err error
+			pv, err = instantiateGeneric(fset, set, curr.t)
+			if err != nil {
+				ec.add(err)
+				index.Set(curr.t, errAbort)
+				continue
+			}
+		}
+		if pv.IsNil() && opts.assignableGivens && types.IsInterface(curr.t) {
+			i, err := assignableGiven(curr.t, given)
+			if err != nil {
+				ec.add(err)
+				index.Set(curr.t, errAbort)
+				continue
+			}
+			if i != -1 {
+				index.Set(curr.t, i)
+				continue
+			}
+		}
+		if pv.IsNil() && opts.autoBind && types.IsInterface(curr.t) {
+			concrete, err := autoBinding(curr.t, set)
+			if err != nil {
+				ec.add(err)
+				index.Set(curr.t, errAbort)
+				continue
+			}
+			if concrete != nil {
+				// Like an interface binding, this does not create a call.
+				i := index.At(concrete)
+				if i == nil {
+					stk = append(stk, curr, frame{t: concrete, from: curr.t, up: &curr})
+					continue
+				}
+				index.Set(curr.t, i)
+				continue
+			}
+		}
+		if pv.IsNil() {
+			src, kind, err := derivedSource(curr.t, set, given, opts)
+			if err != nil {
+				ec.add(err)
+				index.Set(curr.t, errAbort)
+				continue
+			}
+			if src != nil {
+				v := index.At(src)
+				if v == nil {
+					curr.derived = true
+					stk = append(stk, curr, frame{t: src, from: curr.t, up: &curr})
+					continue
+				}
+				if v == errAbort {
+					index.Set(curr.t, errAbort)
+					continue
+				}
+				index.Set(curr.t, given.Len()+len(calls))
+				calls = append(calls, call{
+					kind: kind,
+					out:  curr.t,
+					args: []int{v.(int)},
+					ins:  []types.Type{src},
+				})
+				continue
+			}
+		}
+		if pv.IsNil() && opts.givenFields {
+			i, field, err := givenField(curr.t, given)
+			if err != nil {
+				ec.add(err)
+				index.Set(curr.t, errAbort)
+				continue
+			}
+			if field != nil {
+				index.Set(curr.t, given.Len()+len(calls))
+				calls = append(calls, call{
+					kind: selectorExpr,
+					pkg:  field.Pkg(),
+					name: field.Name(),
+					out:  curr.t,
+					args: []int{i},
+					ins:  []types.Type{given.At(i).Type()},
+				})
+				continue
+			}
+		}
+		// outputStruct is true if the injector's output is built from its
+		// fields because nothing else provides it.
+		outputStruct := false
+		if pv.IsNil() && curr.up == nil && opts.structOutput {
+			if p := outputStructProvider(curr.t); p != nil {
+				pv = ProvidedType{t: curr.t, p: p}
+				outputStruct = true
+			}
+		}
+		if pv.IsNil() {
+			hint := ""
+			if opts.autoAddress && !set.For(types.NewPointer(curr.t)).IsNil() {
+				hint = fmt.Sprintf(" (%s is provided, but Wire does not dereference pointers since the pointer may be nil)", types.TypeString(types.NewPointer(curr.t), nil))
+			}
+			if v := convertibleGiven(curr.t, given); v != nil {
+				hint += fmt.Sprintf(" (injector argument %s has type %s, which is assignable to it but not identical; a provider must convert it)", v.Name(), types.TypeString(v.Type(), nil))
+			}
+			if curr.from != nil {
+				hint += receiverHint(set, curr.t, curr.from)
+			}
+			if curr.up == nil {
+				ec.add(fmt.Errorf("no provider found for %s, output of injector%s%s", types.TypeString(curr.t, nil), hint, opts.suggestion(curr.t)))
 				index.Set(curr.t, errAbort)
 				continue
 			}
 			sb := new(strings.Builder)
-			fmt.Fprintf(sb, "no provider found for %s", types.TypeString(curr.t, nil))
+			fmt.Fprintf(sb, "no provider found for %s%s", types.TypeString(curr.t, nil), hint)
 			for f := curr.up; f != nil; f = f.up {
+				if f.inv != nil {
+					fmt.Fprintf(sb, "\nneeded by wire.Invoke(%s) at %s", f.inv.Name, fset.Position(f.inv.Pos))
+					continue
+				}
+				if f.derived {
+					fmt.Fprintf(sb, "\nneeded by %s, which is derived from it", types.TypeString(f.t, nil))
+					continue
+				}
+				if f.from == nil && set.srcMap.At(f.t) == nil {
+					fmt.Fprintf(sb, "\nneeded by %s, output of injector", types.TypeString(f.t, nil))
+					continue
+				}
 				fmt.Fprintf(sb, "\nneeded by %s in %s", types.TypeString(f.t, nil), set.srcMap.At(f.t).(*providerSetSrc).description(fset, f.t))
 			}
+			sb.WriteString(opts.suggestion(curr.t))
 			ec.add(errors.New(sb.String()))
 			index.Set(curr.t, errAbort)
 			continue
 		}
-		src := set.srcMap.At(curr.t).(*providerSetSrc)
-		used = append(used, src)
+		if !outputStruct {
+			used = append(used, set.srcMap.At(curr.t).(*providerSetSrc))
+		}
 		if concrete := pv.Type(); !types.Identical(concrete, curr.t) {
 			// Interface binding does not create a call.
 			i := index.At(concrete)
@@ -152,7 +411,7 @@ dfs:
 			continue
 		}
 
-		switch pv := set.For(curr.t); {
+		switch {
 		case pv.IsArg():
 			// Continue, already added to stk.
 		case pv.IsProvider():
@@ -186,27 +445,37 @@ dfs:
 				}
 				args[i] = v.(int)
 			}
-			index.Set(curr.t, given.Len()+len(calls))
-			kind := funcProviderCall
-			fieldNames := []string(nil)
-			if p.IsStruct {
-				kind = structProvider
-				for _, arg := range p.Args {
-					fieldNames = append(fieldNames, arg.FieldName)
+			if len(p.Out) == 1 || p.IsStruct {
+				index.Set(curr.t, given.Len()+len(calls))
+				calls = append(calls, providerCall(p, args, ins, curr.t))
+				continue
+			}
+			// A provider that returns several values is called once, and
+			// each of its other results is a step of its own.
+			tc, ok := tupleCalls[p]
+			if !ok {
+				tc = given.Len() + len(calls)
+				c := providerCall(p, args, ins, p.Out[0])
+				c.outs = p.Out
+				calls = append(calls, c)
+				tupleCalls[p] = tc
+			}
+			if types.Identical(curr.t, p.Out[0]) {
+				index.Set(curr.t, tc)
+				continue
+			}
+			for k, t := range p.Out {
+				if types.Identical(curr.t, t) {
+					index.Set(curr.t, given.Len()+len(calls))
+					calls = append(calls, call{
+						kind:   resultExpr,
+						out:    curr.t,
+						args:   []int{tc},
+						ins:    []types.Type{p.Out[0]},
+						result: k,
+					})
 				}
 			}
-			calls = append(calls, call{
-				kind:       kind,
-				pkg:        p.Pkg,
-				name:       p.Name,
-				args:       args,
-				varargs:    p.Varargs,
-				fieldNames: fieldNames,
-				ins:        ins,
-				out:        curr.t,
-				hasCleanup: p.HasCleanup,
-				hasErr:     p.HasErr,
-			})
 		case pv.IsValue():
 			v := pv.Value()
 			index.Set(curr.t, given.Len()+len(calls))
@@ -243,6 +512,81 @@ dfs:
 				args:       args,
 				ptrToField: ptrToField,
 			})
+		case pv.IsCollection():
+			c := pv.Collection()
+			// An element provider that also provides its type to the set
+			// is only called once, and its value is shared with the other
+			// consumers of the type.
+			provided := func(p *Provider) bool {
+				pt := set.For(p.Out[0])
+				return pt.IsProvider() && pt.Provider() == p
+			}
+			// Ensure that the arguments of every element provider have been
+			// visited, in the same way as for a single provider.
+			visitedArgs := true
+			for i := len(c.Providers) - 1; i >= 0; i-- {
+				p := c.Providers[i]
+				if provided(p) {
+					if index.At(p.Out[0]) == nil {
+						if visitedArgs {
+							stk = append(stk, curr)
+							visitedArgs = false
+						}
+						stk = append(stk, frame{t: p.Out[0], from: curr.t, up: &curr})
+					}
+					continue
+				}
+				for j := len(p.Args) - 1; j >= 0; j-- {
+					a := p.Args[j]
+					if index.At(a.Type) == nil {
+						if visitedArgs {
+							stk = append(stk, curr)
+							visitedArgs = false
+						}
+						stk = append(stk, frame{t: a.Type, from: curr.t, up: &curr})
+					}
+				}
+			}
+			if !visitedArgs {
+				continue
+			}
+			var elems []int
+			var elemTypes []types.Type
+			for _, p := range c.Providers {
+				if provided(p) {
+					v := index.At(p.Out[0])
+					if v == errAbort {
+						index.Set(curr.t, errAbort)
+						continue dfs
+					}
+					elems = append(elems, v.(int))
+					elemTypes = append(elemTypes, p.Out[0])
+					continue
+				}
+				args := make([]int, len(p.Args))
+				ins := make([]types.Type, len(p.Args))
+				for i := range p.Args {
+					ins[i] = p.Args[i].Type
+					v := index.At(p.Args[i].Type)
+					if v == errAbort {
+						index.Set(curr.t, errAbort)
+						continue dfs
+					}
+					args[i] = v.(int)
+				}
+				// Element calls are not recorded in index, since the
+				// element providers do not provide their types to the set.
+				elems = append(elems, given.Len()+len(calls))
+				elemTypes = append(elemTypes, p.Out[0])
+				calls = append(calls, providerCall(p, args, ins, p.Out[0]))
+			}
+			index.Set(curr.t, given.Len()+len(calls))
+			calls = append(calls, call{
+				kind: collectExpr,
+				out:  curr.t,
+				args: elems,
+				ins:  elemTypes,
+			})
 		default:
 			panic("unknown return value from ProviderSet.For")
 		}
@@ -250,14 +594,569 @@ dfs:
 	if len(ec.errors) > 0 {
 		return nil, ec.errors
 	}
+	if len(set.Invocations) > 0 {
+		// The output must be the last step, after the invoked functions.
+		switch i := index.At(out).(int); {
+		case i < given.Len():
+			return nil, []error{fmt.Errorf("injector output %s is an injector argument, so wire.Invoke cannot be used", types.TypeString(out, nil))}
+		case i != given.Len()+len(calls)-1:
+			return nil, []error{fmt.Errorf("injector output %s is needed by a wire.Invoke function, which must be called before the output is built", types.TypeString(out, nil))}
+		}
+	}
 	if errs := verifyArgsUsed(set, used); len(errs) > 0 {
 		return nil, errs
 	}
 	return calls, nil
 }
 
+// outputStructProvider returns a provider that builds t, the output of an
+// injector, by filling in its exported fields, or nil if t is not a named
+// struct or a pointer to one. Fields tagged with `wire:"-"` are left unset.
+func outputStructProvider(t types.Type) *Provider {
+	elem := t
+	if ptr, ok := t.(*types.Pointer); ok {
+		elem = ptr.Elem()
+	}
+	named, ok := elem.(*types.Named)
+	if !ok || named.TypeArgs().Len() > 0 {
+		return nil
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	obj := named.Obj()
+	p := &Provider{
+		Pkg:      obj.Pkg(),
+		Name:     obj.Name(),
+		Pos:      obj.Pos(),
+		IsStruct: true,
+		Out:      []types.Type{t},
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Exported() || isPrevented(st.Tag(i)) {
+			continue
+		}
+		p.Args = append(p.Args, ProviderInput{
+			Type:      f.Type(),
+			FieldName: f.Name(),
+		})
+	}
+	if len(p.Args) == 0 {
+		return nil
+	}
+	return p
+}
+
+// providerCall returns the call to provider p that produces out from the
+// values at the indices in args, which have the types in ins.
+func providerCall(p *Provider, args []int, ins []types.Type, out types.Type) call {
+	kind := funcProviderCall
+	fieldNames := []string(nil)
+	if p.IsStruct {
+		kind = structProvider
+		for _, arg := range p.Args {
+			fieldNames = append(fieldNames, arg.FieldName)
+		}
+	}
+	return call{
+		kind:       kind,
+		pkg:        p.Pkg,
+		name:       p.Name,
+		pos:        p.Pos,
+		args:       args,
+		varargs:    p.Varargs,
+		fieldNames: fieldNames,
+		ins:        ins,
+		out:        out,
+		hasCleanup: p.HasCleanup,
+		hasErr:     p.HasErr,
+		errType:    p.ErrType,
+		typeArgs:   p.TypeArgs,
+		method:     p.IsMethod,
+	}
+}
+
+// reorderCalls returns calls, which must be in the order returned by solve,
+// reordered to reduce the number of values that are live at once. It uses
+// the Sethi-Ullman ordering: the arguments of each call are produced in
+// decreasing order of the number of values needed to produce them. The
+// invoked functions keep their order, and the last call stays last.
+func reorderCalls(calls []call, numGiven int) []call {
+	if len(calls) < 2 {
+		return calls
+	}
+	// callArgs returns the distinct arguments of calls[i] that are calls.
+	callArgs := func(i int) []int {
+		var args []int
+	args:
+		for _, a := range calls[i].args {
+			if a < numGiven {
+				continue
+			}
+			for _, prev := range args {
+				if prev == a-numGiven {
+					continue args
+				}
+			}
+			args = append(args, a-numGiven)
+		}
+		return args
+	}
+	// Calls only refer to earlier calls, so needs can be computed in order.
+	need := make([]int, len(calls))
+	sortedArgs := make([][]int, len(calls))
+	for i := range calls {
+		args := callArgs(i)
+		sort.SliceStable(args, func(j, k int) bool {
+			return need[args[j]] > need[args[k]]
+		})
+		sortedArgs[i] = args
+		need[i] = 1
+		for j, a := range args {
+			if need[a]+j > need[i] {
+				need[i] = need[a] + j
+			}
+		}
+	}
+	order := make([]int, 0, len(calls))
+	visited := make([]bool, len(calls))
+	var visit func(int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, a := range sortedArgs[i] {
+			visit(a)
+		}
+		order = append(order, i)
+	}
+	// Invoked functions produce nothing that another call takes, so each
+	// one is a root of its own, before the output.
+	for i := range calls {
+		if calls[i].kind == invokeCall {
+			visit(i)
+		}
+	}
+	visit(len(calls) - 1)
+	if len(order) != len(calls) {
+		// Not every call contributes to the output; keep the original order.
+		return calls
+	}
+	newIndex := make([]int, len(calls))
+	for i, old := range order {
+		newIndex[old] = i
+	}
+	reordered := make([]call, len(calls))
+	for i, old := range order {
+		c := calls[old]
+		c.args = make([]int, len(calls[old].args))
+		for j, a := range calls[old].args {
+			if a < numGiven {
+				c.args[j] = a
+			} else {
+				c.args[j] = numGiven + newIndex[a-numGiven]
+			}
+		}
+		reordered[i] = c
+	}
+	return reordered
+}
+
+// derivedSource returns the type that t can be derived from, and the kind
+// of call that derives it, when t is not provided directly. It returns a nil
+// type if opts does not allow deriving t.
+func derivedSource(t types.Type, set *ProviderSet, given *types.Tuple, opts *solveOptions) (types.Type, callKind, error) {
+	if opts.convertBasic {
+		src, err := basicConversionSource(t, set, given)
+		if err != nil || src != nil {
+			return src, convertExpr, err
+		}
+	}
+	if opts.autoAddress {
+		if ptr, ok := t.(*types.Pointer); ok && (!set.For(ptr.Elem()).IsNil() || tupleIndex(given, ptr.Elem()) != -1) {
+			return ptr.Elem(), addressExpr, nil
+		}
+	}
+	if opts.autoDeref {
+		if ptr := types.NewPointer(t); !set.For(ptr).IsNil() || tupleIndex(given, ptr) != -1 {
+			return ptr, derefExpr, nil
+		}
+	}
+	return nil, 0, nil
+}
+
+// basicConversionSource returns the type that t can be converted from when
+// t is not provided directly, or nil if there is none. Only a single level of
+// conversion between a defined type and its underlying basic type is
+// considered: a defined type is converted from its underlying type, and a
+// basic type is converted from the one defined type over it that is provided.
+func basicConversionSource(t types.Type, set *ProviderSet, given *types.Tuple) (types.Type, error) {
+	if _, ok := t.(*types.Named); ok {
+		u, ok := t.Underlying().(*types.Basic)
+		if !ok {
+			return nil, nil
+		}
+		if !set.For(u).IsNil() || tupleIndex(given, u) != -1 {
+			return u, nil
+		}
+		return nil, nil
+	}
+	if _, ok := t.(*types.Basic); !ok {
+		return nil, nil
+	}
+	candidates := set.Outputs()
+	for i := 0; i < given.Len(); i++ {
+		candidates = append(candidates, given.At(i).Type())
+	}
+	var srcs []types.Type
+candidates:
+	for _, c := range candidates {
+		if _, ok := c.(*types.Named); !ok || !types.Identical(c.Underlying(), t) {
+			continue
+		}
+		for _, s := range srcs {
+			if types.Identical(s, c) {
+				continue candidates
+			}
+		}
+		srcs = append(srcs, c)
+	}
+	switch len(srcs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return srcs[0], nil
+	}
+	names := make([]string, len(srcs))
+	for i, c := range srcs {
+		names[i] = types.TypeString(c, nil)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("cannot convert to %s: it could be converted from any of %s", types.TypeString(t, nil), strings.Join(names, ", "))
+}
+
+// givenField finds the exported field of type t in the struct (or pointer
+// to struct) arguments in given. It returns the index of the argument and the
+// field, or a nil field if there is none. It is an error for more than one
+// field to have type t.
+func givenField(t types.Type, given *types.Tuple) (int, *types.Var, error) {
+	idx := -1
+	var found *types.Var
+	var matches []string
+	for i := 0; i < given.Len(); i++ {
+		gt := given.At(i).Type()
+		st, ok := gt.Underlying().(*types.Struct)
+		if !ok {
+			if ptr, isPtr := gt.Underlying().(*types.Pointer); isPtr {
+				st, ok = ptr.Elem().Underlying().(*types.Struct)
+			}
+		}
+		if !ok {
+			continue
+		}
+		for j := 0; j < st.NumFields(); j++ {
+			f := st.Field(j)
+			if !f.Exported() || !types.Identical(f.Type(), t) {
+				continue
+			}
+			idx, found = i, f
+			matches = append(matches, fmt.Sprintf("%s.%s", types.TypeString(gt, nil), f.Name()))
+		}
+	}
+	if len(matches) > 1 {
+		return 0, nil, fmt.Errorf("%s is provided by more than one field of the injector arguments: %s", types.TypeString(t, nil), strings.Join(matches, ", "))
+	}
+	return idx, found, nil
+}
+
+// assignableGiven returns the index of the injector argument in given that
+// is assignable to the interface type t, or -1 if there is none. It is an
+// error for more than one argument to be assignable to t.
+func assignableGiven(t types.Type, given *types.Tuple) (int, error) {
+	idx := -1
+	var matches []string
+	for i := 0; i < given.Len(); i++ {
+		v := given.At(i)
+		if !types.AssignableTo(v.Type(), t) {
+			continue
+		}
+		idx = i
+		matches = append(matches, fmt.Sprintf("%s %s", v.Name(), types.TypeString(v.Type(), nil)))
+	}
+	if len(matches) > 1 {
+		return -1, fmt.Errorf("more than one injector argument implements %s: %s", types.TypeString(t, nil), strings.Join(matches, ", "))
+	}
+	return idx, nil
+}
+
+// convertibleGiven returns the injector argument in given whose type is
+// assignable to the non-interface type t without being identical to it, as
+// []string is to "type Args []string", or nil if there is none. Such an
+// argument was probably meant to provide t, but needs a conversion.
+func convertibleGiven(t types.Type, given *types.Tuple) *types.Var {
+	if types.IsInterface(t) {
+		return nil
+	}
+	for i := 0; i < given.Len(); i++ {
+		v := given.At(i)
+		if !types.Identical(v.Type(), t) && types.AssignableTo(v.Type(), t) {
+			return v
+		}
+	}
+	return nil
+}
+
+// builtinTypeWarnings returns a warning for each provider called by the
+// injector with the given name whose output is a predeclared type like
+// string or int that another provider takes, since the meaning of such a
+// value is not part of its type and any other provider of the type would
+// conflict with it.
+func builtinTypeWarnings(fset *token.FileSet, name string, calls []call, numGiven int) []error {
+	var warnings []error
+	for i, c := range calls {
+		if c.kind != funcProviderCall && c.kind != structProvider || !c.pos.IsValid() {
+			continue
+		}
+		basic, ok := c.out.(*types.Basic)
+		if !ok {
+			continue
+		}
+		var user string
+	users:
+		for _, d := range calls[i+1:] {
+			if d.kind != funcProviderCall && d.kind != structProvider && d.kind != invokeCall {
+				continue
+			}
+			for _, a := range d.args {
+				if a == numGiven+i {
+					user = d.name
+					break users
+				}
+			}
+		}
+		if user == "" {
+			continue
+		}
+		// Suggest a type named after the provider, as in provideDSN.
+		typeName := c.name
+		for _, prefix := range []string{"provide", "Provide", "New", "new"} {
+			typeName = strings.TrimPrefix(typeName, prefix)
+		}
+		if typeName == "" || !token.IsExported(typeName) {
+			typeName = "Value"
+		}
+		warnings = append(warnings, notePosition(fset.Position(c.pos), fmt.Errorf("inject %s: provider %s provides %s, which %s takes; declare a named type for the value, like \"type %s %s\", so that it cannot be confused with other %s values", name, c.name, basic.Name(), user, typeName, basic.Name(), basic.Name())))
+	}
+	return warnings
+}
+
+// convertibleArgWarnings returns a warning for each parameter of a provider
+// called by the injector with the given name that is not satisfied by an
+// injector argument although one in given is assignable to it, since the
+// argument is then silently ignored in favor of another provider.
+func convertibleArgWarnings(fset *token.FileSet, name string, calls []call, given *types.Tuple) []error {
+	var warnings []error
+	for _, c := range calls {
+		if c.kind != funcProviderCall && c.kind != structProvider || !c.pos.IsValid() {
+			continue
+		}
+		for j, a := range c.args {
+			if a < given.Len() {
+				continue
+			}
+			if v := convertibleGiven(c.ins[j], given); v != nil {
+				warnings = append(warnings, notePosition(fset.Position(c.pos), fmt.Errorf("inject %s: provider %s takes %s, which argument %s of type %s is assignable to but not identical with, so the argument is not used for it", name, c.name, types.TypeString(c.ins[j], nil), v.Name(), types.TypeString(v.Type(), nil))))
+			}
+		}
+	}
+	return warnings
+}
+
+// instantiateGeneric instantiates the generic provider in set or its
+// imports whose output can be t, and adds it to set so that the other types
+// it produces come from the same call. It returns the zero ProvidedType if
+// no generic provider matches. It is an error for more than one to match.
+func instantiateGeneric(fset *token.FileSet, set *ProviderSet, t types.Type) (ProvidedType, error) {
+	type match struct {
+		g *GenericProvider
+		// imp is the set imported by set that includes g, or nil if g is
+		// in set itself.
+		imp  *ProviderSet
+		args []types.Type
+	}
+	var matches []match
+	seen := make(map[*types.Func]bool)
+	var visit func(s, imp *ProviderSet)
+	visit = func(s, imp *ProviderSet) {
+		for _, g := range s.Generics {
+			if seen[g.Func] {
+				continue
+			}
+			seen[g.Func] = true
+			if args := inferTypeArgs(g, t); args != nil {
+				matches = append(matches, match{g: g, imp: imp, args: args})
+			}
+		}
+		for _, sub := range append(s.Imports[:len(s.Imports):len(s.Imports)], s.Fallbacks...) {
+			if imp == nil {
+				visit(sub, sub)
+			} else {
+				visit(sub, imp)
+			}
+		}
+	}
+	visit(set, nil)
+	if len(matches) == 0 {
+		return ProvidedType{}, nil
+	}
+	if len(matches) > 1 {
+		var names []string
+		for _, m := range matches {
+			names = append(names, fmt.Sprintf("%s.%s (%s)", m.g.Func.Pkg().Name(), m.g.Func.Name(), fset.Position(m.g.Func.Pos())))
+		}
+		return ProvidedType{}, fmt.Errorf("more than one generic provider can provide %s: %s", types.TypeString(t, nil), strings.Join(names, ", "))
+	}
+	m := matches[0]
+	inst, err := types.Instantiate(nil, m.g.Func.Type(), m.args, true)
+	if err != nil {
+		return ProvidedType{}, notePosition(fset.Position(m.g.Func.Pos()), fmt.Errorf("instantiate %s for %s: %v", m.g.Func.Name(), types.TypeString(t, nil), err))
+	}
+	p, errs := processFuncInstanceProvider(fset, m.g.Func, inst.(*types.Signature), m.args)
+	if len(errs) > 0 {
+		return ProvidedType{}, errs[0]
+	}
+	src := &providerSetSrc{Provider: p}
+	if m.imp != nil {
+		src = &providerSetSrc{Import: m.imp}
+	}
+	for _, out := range p.Out {
+		if set.providerMap.At(out) == nil {
+			set.providerMap.Set(out, &ProvidedType{t: out, p: p})
+			set.srcMap.Set(out, src)
+		}
+	}
+	return set.For(t), nil
+}
+
+// inferTypeArgs returns the type arguments with which one of the outputs of
+// g is t, or nil if there are none.
+func inferTypeArgs(g *GenericProvider, t types.Type) []types.Type {
+	params := g.Func.Type().(*types.Signature).TypeParams()
+outs:
+	for _, out := range g.Out {
+		args := make([]types.Type, params.Len())
+		if !unify(out, t, params, args) {
+			continue
+		}
+		for _, a := range args {
+			if a == nil {
+				// A type parameter that only appears in the arguments
+				// cannot be inferred.
+				continue outs
+			}
+		}
+		return args
+	}
+	return nil
+}
+
+// unify reports whether x, which may refer to the type parameters in
+// params, matches t once they are replaced by args. Type parameters that
+// are not yet in args are filled in from t.
+func unify(x, t types.Type, params *types.TypeParamList, args []types.Type) bool {
+	switch x := x.(type) {
+	case *types.TypeParam:
+		if i := x.Index(); i < params.Len() && params.At(i) == x {
+			if args[i] == nil {
+				args[i] = t
+				return true
+			}
+			return types.Identical(args[i], t)
+		}
+	case *types.Pointer:
+		t, ok := t.(*types.Pointer)
+		return ok && unify(x.Elem(), t.Elem(), params, args)
+	case *types.Slice:
+		t, ok := t.(*types.Slice)
+		return ok && unify(x.Elem(), t.Elem(), params, args)
+	case *types.Array:
+		t, ok := t.(*types.Array)
+		return ok && x.Len() == t.Len() && unify(x.Elem(), t.Elem(), params, args)
+	case *types.Map:
+		t, ok := t.(*types.Map)
+		return ok && unify(x.Key(), t.Key(), params, args) && unify(x.Elem(), t.Elem(), params, args)
+	case *types.Chan:
+		t, ok := t.(*types.Chan)
+		return ok && x.Dir() == t.Dir() && unify(x.Elem(), t.Elem(), params, args)
+	case *types.Named:
+		t, ok := t.(*types.Named)
+		if !ok || x.Origin() != t.Origin() || x.TypeArgs().Len() != t.TypeArgs().Len() {
+			return false
+		}
+		for i := 0; i < x.TypeArgs().Len(); i++ {
+			if !unify(x.TypeArgs().At(i), t.TypeArgs().At(i), params, args) {
+				return false
+			}
+		}
+		return true
+	}
+	return types.Identical(x, t)
+}
+
+// autoBinding returns the type provided by set that implements the
+// interface type t, or nil if there is none. Interface types are not
+// considered. It is an error for more than one type to implement t.
+func autoBinding(t types.Type, set *ProviderSet) (types.Type, error) {
+	var found types.Type
+	var matches []string
+	for _, out := range set.Outputs() {
+		if types.IsInterface(out) || !types.AssignableTo(out, t) {
+			continue
+		}
+		found = out
+		matches = append(matches, types.TypeString(out, nil))
+	}
+	if len(matches) > 1 {
+		sort.Strings(matches)
+		return nil, fmt.Errorf("more than one provided type implements %s: %s; use wire.Bind to choose one", types.TypeString(t, nil), strings.Join(matches, ", "))
+	}
+	return found, nil
+}
+
+// tupleIndex returns the index of the first element of tuple with a type
+// identical to t, or -1 if there is none.
+func tupleIndex(tuple *types.Tuple, t types.Type) int {
+	for i := 0; i < tuple.Len(); i++ {
+		if types.Identical(tuple.At(i).Type(), t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// receiverHint explains a missing provider for t that is the receiver of
+// the method providing from, if it is one.
+func receiverHint(set *ProviderSet, t, from types.Type) string {
+	pv := set.For(from)
+	if !pv.IsProvider() {
+		return ""
+	}
+	p := pv.Provider()
+	if !p.IsMethod || !types.Identical(p.Args[0].Type, t) {
+		return ""
+	}
+	if ptr, ok := t.(*types.Pointer); ok && !set.For(ptr.Elem()).IsNil() {
+		return fmt.Sprintf(" (receiver of method %s; %s is provided, but the method has a pointer receiver)", p.Name, types.TypeString(ptr.Elem(), nil))
+	}
+	return fmt.Sprintf(" (receiver of method %s)", p.Name)
+}
+
 // verifyArgsUsed ensures that all of the arguments in set were used during solve.
 func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
+	used = append(used[:len(used):len(used)], set.shadowed...)
 	var errs []error
 	for _, imp := range set.Imports {
 		found := false
@@ -287,6 +1186,18 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 			errs = append(errs, fmt.Errorf("unused provider %q", p.Pkg.Name()+"."+p.Name))
 		}
 	}
+	for _, g := range set.Generics {
+		found := false
+		for _, u := range used {
+			if u.Provider != nil && len(u.Provider.TypeArgs) > 0 && u.Provider.Pkg == g.Func.Pkg() && u.Provider.Name == g.Func.Name() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("unused provider %q", g.Func.Pkg().Name()+"."+g.Func.Name()))
+		}
+	}
 	for _, v := range set.Values {
 		found := false
 		for _, u := range used {
@@ -323,17 +1234,111 @@ func verifyArgsUsed(set *ProviderSet, used []*providerSetSrc) []error {
 			errs = append(errs, fmt.Errorf("unused field %q.%s", f.Parent, f.Name))
 		}
 	}
+	for _, c := range set.Collections {
+		found := false
+		for _, u := range used {
+			if u.Collection == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("unused collection of %s: no provider in the set takes it as an argument", types.TypeString(c.Out, nil)))
+		}
+	}
 	return errs
 }
 
+// mergeCollection adds the providers of c to the collection that provides
+// t in providerMap, if both are collections, and reports whether it did.
+// The merged collection calls the providers of the existing collection
+// first, and calls each provider only once.
+func mergeCollection(providerMap *typeutil.Map, t types.Type, c *Collection) bool {
+	prev := providerMap.At(t).(*ProvidedType)
+	if c == nil || !prev.IsCollection() {
+		return false
+	}
+	merged := &Collection{
+		Pos:       prev.c.Pos,
+		Out:       prev.c.Out,
+		Providers: append([]*Provider(nil), prev.c.Providers...),
+	}
+	for _, p := range c.Providers {
+		dup := false
+		for _, q := range merged.Providers {
+			if p == q {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			merged.Providers = append(merged.Providers, p)
+		}
+	}
+	providerMap.Set(t, &ProvidedType{t: t, c: merged})
+	return true
+}
+
 // buildProviderMap creates the providerMap and srcMap fields for a given
 // provider set. The given provider set's providerMap and srcMap fields are
-// ignored.
-func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *ProviderSet) (*typeutil.Map, *typeutil.Map, []error) {
+// ignored. The sources replaced by the set's overrides, dropped in favor of
+// its preferences, or merged into another collection of the same slice type
+// are recorded in its shadowed field.
+func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *ProviderSet, strictBindings bool) (*typeutil.Map, *typeutil.Map, []error) {
 	providerMap := new(typeutil.Map)
 	providerMap.SetHasher(hasher)
 	srcMap := new(typeutil.Map) // to *providerSetSrc
 	srcMap.SetHasher(hasher)
+	set.shadowed = nil
+
+	// preferred resolves a conflict for typ between src, which would provide
+	// pt, and the source already in srcMap using set's preferences. It
+	// reports whether the conflict was resolved.
+	usedPrefs := make(map[*Preference]bool)
+	preferred := func(typ types.Type, src *providerSetSrc, pt *ProvidedType) bool {
+		prevSrc := srcMap.At(typ).(*providerSetSrc)
+		prevPT := providerMap.At(typ).(*ProvidedType)
+		for _, pref := range set.Preferences {
+			switch {
+			case prevPT.IsProvider() && prevPT.Provider() == pref.Provider:
+				set.shadowed = append(set.shadowed, src)
+			case pt != nil && pt.IsProvider() && pt.Provider() == pref.Provider:
+				set.shadowed = append(set.shadowed, prevSrc)
+				providerMap.Set(typ, pt)
+				srcMap.Set(typ, src)
+			default:
+				continue
+			}
+			usedPrefs[pref] = true
+			return true
+		}
+		return false
+	}
+
+	// direct resolves a conflict for typ between src, which would provide
+	// pt, and the source already in srcMap in favor of the one that provides
+	// typ itself rather than through an interface binding, unless
+	// strictBindings is set. It reports whether the conflict was resolved.
+	direct := func(typ types.Type, src *providerSetSrc, pt *ProvidedType) bool {
+		if strictBindings {
+			return false
+		}
+		prevSrc := srcMap.At(typ).(*providerSetSrc)
+		prevPT := providerMap.At(typ).(*ProvidedType)
+		prevBound := prevSrc.Binding != nil || !types.Identical(prevPT.Type(), typ)
+		bound := src.Binding != nil || !types.Identical(pt.Type(), typ)
+		switch {
+		case bound && !prevBound:
+			set.shadowed = append(set.shadowed, src)
+		case prevBound && !bound:
+			set.shadowed = append(set.shadowed, prevSrc)
+			providerMap.Set(typ, pt)
+			srcMap.Set(typ, src)
+		default:
+			return false
+		}
+		return true
+	}
 
 	ec := new(errorCollector)
 	// Process injector arguments.
@@ -356,6 +1361,18 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 		src := &providerSetSrc{Import: imp}
 		imp.providerMap.Iterate(func(k types.Type, v interface{}) {
 			if prevSrc := srcMap.At(k); prevSrc != nil {
+				if src.sameOrigin(prevSrc.(*providerSetSrc), k) {
+					// The set was already imported along another path.
+					set.shadowed = append(set.shadowed, src)
+					return
+				}
+				if preferred(k, src, v.(*ProvidedType)) || direct(k, src, v.(*ProvidedType)) {
+					return
+				}
+				if mergeCollection(providerMap, k, v.(*ProvidedType).c) {
+					set.shadowed = append(set.shadowed, src)
+					return
+				}
 				ec.add(bindingConflictError(fset, k, set, src, prevSrc.(*providerSetSrc)))
 				return
 			}
@@ -372,7 +1389,9 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 		src := &providerSetSrc{Provider: p}
 		for _, typ := range p.Out {
 			if prevSrc := srcMap.At(typ); prevSrc != nil {
-				ec.add(bindingConflictError(fset, typ, set, src, prevSrc.(*providerSetSrc)))
+				if pt := (&ProvidedType{t: typ, p: p}); !preferred(typ, src, pt) && !direct(typ, src, pt) {
+					ec.add(bindingConflictError(fset, typ, set, src, prevSrc.(*providerSetSrc)))
+				}
 				continue
 			}
 			providerMap.Set(typ, &ProvidedType{t: typ, p: p})
@@ -382,6 +1401,9 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 	for _, v := range set.Values {
 		src := &providerSetSrc{Value: v}
 		if prevSrc := srcMap.At(v.Out); prevSrc != nil {
+			if preferred(v.Out, src, nil) || direct(v.Out, src, &ProvidedType{t: v.Out, v: v}) {
+				continue
+			}
 			ec.add(bindingConflictError(fset, v.Out, set, src, prevSrc.(*providerSetSrc)))
 			continue
 		}
@@ -392,6 +1414,9 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 		src := &providerSetSrc{Field: f}
 		for _, typ := range f.Out {
 			if prevSrc := srcMap.At(typ); prevSrc != nil {
+				if preferred(typ, src, nil) || direct(typ, src, &ProvidedType{t: typ, f: f}) {
+					continue
+				}
 				ec.add(bindingConflictError(fset, typ, set, src, prevSrc.(*providerSetSrc)))
 				continue
 			}
@@ -399,6 +1424,49 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 			srcMap.Set(typ, src)
 		}
 	}
+	for _, c := range set.Collections {
+		src := &providerSetSrc{Collection: c}
+		if prevSrc := srcMap.At(c.Out); prevSrc != nil {
+			if preferred(c.Out, src, nil) || direct(c.Out, src, &ProvidedType{t: c.Out, c: c}) {
+				continue
+			}
+			if mergeCollection(providerMap, c.Out, c) {
+				set.shadowed = append(set.shadowed, src)
+				continue
+			}
+			ec.add(bindingConflictError(fset, c.Out, set, src, prevSrc.(*providerSetSrc)))
+			continue
+		}
+		providerMap.Set(c.Out, &ProvidedType{t: c.Out, c: c})
+		srcMap.Set(c.Out, src)
+	}
+	if len(ec.errors) > 0 {
+		return nil, nil, ec.errors
+	}
+
+	// Process overrides, which take precedence over everything but injector
+	// arguments and each other.
+	overridden := new(typeutil.Map) // to *providerSetSrc
+	overridden.SetHasher(hasher)
+	for _, p := range set.Overrides {
+		src := &providerSetSrc{Provider: p}
+		for _, typ := range p.Out {
+			if prevSrc := overridden.At(typ); prevSrc != nil {
+				ec.add(bindingConflictError(fset, typ, set, src, prevSrc.(*providerSetSrc)))
+				continue
+			}
+			if prevSrc := srcMap.At(typ); prevSrc != nil {
+				if prevSrc.(*providerSetSrc).InjectorArg != nil {
+					ec.add(bindingConflictError(fset, typ, set, src, prevSrc.(*providerSetSrc)))
+					continue
+				}
+				set.shadowed = append(set.shadowed, prevSrc.(*providerSetSrc))
+			}
+			providerMap.Set(typ, &ProvidedType{t: typ, p: p})
+			srcMap.Set(typ, src)
+			overridden.Set(typ, src)
+		}
+	}
 	if len(ec.errors) > 0 {
 		return nil, nil, ec.errors
 	}
@@ -407,7 +1475,14 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 	// ensure the concrete type is being provided.
 	for _, b := range set.Bindings {
 		src := &providerSetSrc{Binding: b}
+		if overridden.At(b.Iface) != nil {
+			set.shadowed = append(set.shadowed, src)
+			continue
+		}
 		if prevSrc := srcMap.At(b.Iface); prevSrc != nil {
+			if preferred(b.Iface, src, nil) || direct(b.Iface, src, nil) {
+				continue
+			}
 			ec.add(bindingConflictError(fset, b.Iface, set, src, prevSrc.(*providerSetSrc)))
 			continue
 		}
@@ -423,13 +1498,30 @@ func buildProviderMap(fset *token.FileSet, hasher typeutil.Hasher, set *Provider
 		providerMap.Set(b.Iface, concrete)
 		srcMap.Set(b.Iface, src)
 	}
+	// Process fallbacks last, so that they only provide the types that
+	// nothing else provides.
+	for _, fb := range set.Fallbacks {
+		src := &providerSetSrc{Import: fb}
+		fb.providerMap.Iterate(func(k types.Type, v interface{}) {
+			if srcMap.At(k) != nil {
+				return
+			}
+			providerMap.Set(k, v)
+			srcMap.Set(k, src)
+		})
+	}
+	for _, pref := range set.Preferences {
+		if !usedPrefs[pref] {
+			ec.add(notePosition(fset.Position(pref.Pos), fmt.Errorf("wire.Prefer(%s.%s) does not resolve a conflict between providers", pref.Provider.Pkg.Name(), pref.Provider.Name)))
+		}
+	}
 	if len(ec.errors) > 0 {
 		return nil, nil, ec.errors
 	}
 	return providerMap, srcMap, nil
 }
 
-func verifyAcyclic(providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
+func verifyAcyclic(fset *token.FileSet, providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
 	// We must visit every provider type inside provider map, but we don't
 	// have a well-defined starting point and there may be several
 	// distinct graphs. Thus, we start a depth-first search at every
@@ -463,14 +1555,21 @@ func verifyAcyclic(providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
 				// Leaf: values do not have dependencies.
 			case pt.IsArg():
 				// Injector arguments do not have dependencies.
-			case pt.IsProvider() || pt.IsField():
+			case pt.IsProvider() || pt.IsField() || pt.IsCollection():
 				var args []types.Type
-				if pt.IsProvider() {
+				switch {
+				case pt.IsProvider():
 					for _, arg := range pt.Provider().Args {
 						args = append(args, arg.Type)
 					}
-				} else {
+				case pt.IsField():
 					args = append(args, pt.Field().Parent)
+				default:
+					for _, p := range pt.Collection().Providers {
+						for _, arg := range p.Args {
+							args = append(args, arg.Type)
+						}
+					}
 				}
 				for _, a := range args {
 					hasCycle := false
@@ -480,12 +1579,15 @@ func verifyAcyclic(providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
 							fmt.Fprintf(sb, "cycle for %s:\n", types.TypeString(a, nil))
 							for j := i; j < len(curr); j++ {
 								t := providerMap.At(curr[j]).(*ProvidedType)
-								if t.IsProvider() {
+								switch {
+								case t.IsProvider():
 									p := t.Provider()
-									fmt.Fprintf(sb, "%s (%s.%s) ->\n", types.TypeString(curr[j], nil), p.Pkg.Path(), p.Name)
-								} else {
+									fmt.Fprintf(sb, "%s (%s.%s at %v) ->\n", types.TypeString(curr[j], nil), p.Pkg.Path(), p.Name, fset.Position(p.Pos))
+								case t.IsField():
 									p := t.Field()
-									fmt.Fprintf(sb, "%s (%s.%s) ->\n", types.TypeString(curr[j], nil), p.Parent, p.Name)
+									fmt.Fprintf(sb, "%s (%s.%s at %v) ->\n", types.TypeString(curr[j], nil), p.Parent, p.Name, fset.Position(p.Pos))
+								default:
+									fmt.Fprintf(sb, "%s (wire.Collect at %v) ->\n", types.TypeString(curr[j], nil), fset.Position(t.Collection().Pos))
 								}
 							}
 							fmt.Fprintf(sb, "%s", types.TypeString(a, nil))
@@ -508,13 +1610,19 @@ func verifyAcyclic(providerMap *typeutil.Map, hasher typeutil.Hasher) []error {
 }
 
 // bindingConflictError creates a new error describing multiple bindings
-// for the same output type.
+// for the same output type. Both sources are listed with the chain of sets
+// that brought them into set.
 func bindingConflictError(fset *token.FileSet, typ types.Type, set *ProviderSet, cur, prev *providerSetSrc) error {
-	sb := new(strings.Builder)
-	if set.VarName != "" {
-		fmt.Fprintf(sb, "%s has ", set.VarName)
+	owner := set.VarName
+	switch {
+	case owner != "":
+	case set.InjectorArgs != nil:
+		owner = "injector " + set.InjectorArgs.Name
+	default:
+		owner = "provider set"
 	}
-	fmt.Fprintf(sb, "multiple bindings for %s\n", types.TypeString(typ, nil))
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "%s has multiple bindings for %s\n", owner, types.TypeString(typ, nil))
 	fmt.Fprintf(sb, "current:\n<- %s\n", strings.Join(cur.trace(fset, typ), "\n<- "))
 	fmt.Fprintf(sb, "previous:\n<- %s", strings.Join(prev.trace(fset, typ), "\n<- "))
 	return notePosition(fset.Position(set.Pos), errors.New(sb.String()))
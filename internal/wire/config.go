@@ -0,0 +1,114 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// InjectorSets lists provider sets to add to an injector's provider set, as
+// if they were passed to its wire.Build call.
+type InjectorSets struct {
+	Injector Injector
+	Sets     []ProviderSetID
+}
+
+// ParseConfig parses a JSON configuration file that maps injectors to the
+// provider sets to add to them. Injectors and provider sets are written as
+// the import path of their package followed by a dot and their name:
+//
+//	{
+//		"injectors": {
+//			"example.com/app.initApp": ["example.com/app/db.Set"]
+//		}
+//	}
+//
+// The result is sorted by injector.
+func ParseConfig(data []byte) ([]InjectorSets, error) {
+	var cfg struct {
+		Injectors map[string][]string `json:"injectors"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %v", err)
+	}
+	var result []InjectorSets
+	for name, refs := range cfg.Injectors {
+		path, fn, err := splitQualifiedName(name)
+		if err != nil {
+			return nil, fmt.Errorf("parse config: injector %v", err)
+		}
+		is := InjectorSets{Injector: Injector{ImportPath: path, FuncName: fn}}
+		for _, ref := range refs {
+			path, v, err := splitQualifiedName(ref)
+			if err != nil {
+				return nil, fmt.Errorf("parse config: provider set for %s: %v", name, err)
+			}
+			is.Sets = append(is.Sets, ProviderSetID{ImportPath: path, VarName: v})
+		}
+		result = append(result, is)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Injector.String() < result[j].Injector.String()
+	})
+	return result, nil
+}
+
+// splitQualifiedName splits a name like "example.com/foo.Bar" into the
+// import path and the name, which must be a Go identifier.
+func splitQualifiedName(s string) (path, name string, _ error) {
+	i := strings.LastIndexByte(s, '.')
+	if i <= strings.LastIndexByte(s, '/') || i == len(s)-1 {
+		return "", "", fmt.Errorf("%q is not of the form \"import/path.Name\"", s)
+	}
+	if !token.IsIdentifier(s[i+1:]) {
+		return "", "", fmt.Errorf("%q: %q is not a valid Go identifier", s, s[i+1:])
+	}
+	return s[:i], s[i+1:], nil
+}
+
+// resolveInjectorSets finds the provider sets to add to the injectors of the
+// package with the given import path, keyed by injector name.
+func (oc *objectCache) resolveInjectorSets(pkgPath string, config []InjectorSets) (map[string][]*ProviderSet, []error) {
+	ec := new(errorCollector)
+	sets := make(map[string][]*ProviderSet)
+	for _, is := range config {
+		if is.Injector.ImportPath != pkgPath {
+			continue
+		}
+		for _, id := range is.Sets {
+			pkg := oc.packages[id.ImportPath]
+			if pkg == nil {
+				ec.add(fmt.Errorf("config for injector %s: package %q is not a dependency of the generated package", is.Injector.FuncName, id.ImportPath))
+				continue
+			}
+			obj := pkg.Types.Scope().Lookup(id.VarName)
+			if obj == nil || !isProviderSetType(obj.Type()) {
+				ec.add(fmt.Errorf("config for injector %s: %s is not a provider set", is.Injector.FuncName, id))
+				continue
+			}
+			item, errs := oc.get(obj)
+			if len(errs) > 0 {
+				ec.add(errs...)
+				continue
+			}
+			sets[is.Injector.FuncName] = append(sets[is.Injector.FuncName], item.(*ProviderSet))
+		}
+	}
+	return sets, ec.errors
+}
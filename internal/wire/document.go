@@ -0,0 +1,177 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// SetDoc describes the providers offered by a top-level provider set.
+type SetDoc struct {
+	// ID identifies the provider set.
+	ID ProviderSetID
+	// Providers lists the providers in the set, followed by the providers
+	// of the sets it imports. Each provider appears at most once.
+	Providers []ProviderDoc
+}
+
+// ProviderDoc describes a single provider for documentation purposes.
+type ProviderDoc struct {
+	// ImportPath is the import path of the package declaring the provider.
+	ImportPath string
+	// Name is the name of the provider function or struct type.
+	Name string
+	// Signature is the provider's declaration as it would be written in
+	// its own package, e.g. "func NewFoo(bar *Bar) (*Foo, error)".
+	Signature string
+	// Doc is the text of the doc comment attached to the provider's
+	// declaration, or the empty string if it has none.
+	Doc string
+	// Pos is the source position of the provider's declaration.
+	Pos token.Position
+}
+
+// DocumentSets finds all the provider sets declared as top-level variables
+// in the packages that match the given patterns and describes the
+// providers each of them offers. The arguments are interpreted the same as
+// for Load.
+func DocumentSets(ctx context.Context, wd string, env []string, injectTag, tags string, patterns []string) ([]SetDoc, []error) {
+	pkgs, errs := load(ctx, wd, env, injectTag, tags, patterns)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	oc := newObjectCache(pkgs)
+	ec := new(errorCollector)
+	var docs []SetDoc
+	for _, pkg := range pkgs {
+		if isWireImport(pkg.PkgPath) {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if !isProviderSetType(obj.Type()) {
+				continue
+			}
+			item, errs := oc.get(obj)
+			if len(errs) > 0 {
+				ec.add(notePositionAll(oc.fset.Position(obj.Pos()), errs)...)
+				continue
+			}
+			pset := item.(*ProviderSet)
+			sd := SetDoc{ID: ProviderSetID{ImportPath: pset.PkgPath, VarName: name}}
+			for _, p := range allProviders(pset) {
+				sd.Providers = append(sd.Providers, oc.providerDoc(p))
+			}
+			docs = append(docs, sd)
+		}
+	}
+	return docs, ec.errors
+}
+
+// allProviders returns the providers in set followed by the providers of its
+// imports and fallbacks, visited depth-first. Duplicates are omitted.
+func allProviders(set *ProviderSet) []*Provider {
+	var ps []*Provider
+	seenSets := make(map[*ProviderSet]bool)
+	seen := make(map[*Provider]bool)
+	var visit func(*ProviderSet)
+	visit = func(s *ProviderSet) {
+		if seenSets[s] {
+			return
+		}
+		seenSets[s] = true
+		for _, p := range s.Providers {
+			if !seen[p] {
+				seen[p] = true
+				ps = append(ps, p)
+			}
+		}
+		for _, imp := range s.Imports {
+			visit(imp)
+		}
+		for _, fb := range s.Fallbacks {
+			visit(fb)
+		}
+	}
+	visit(set)
+	return ps
+}
+
+// checkProviderDocs adds a warning to g for each provider function in set
+// that has no doc comment. Each provider is reported at most once.
+func (g *gen) checkProviderDocs(oc *objectCache, set *ProviderSet) {
+	for _, p := range allProviders(set) {
+		if p.IsStruct || g.docChecked[p] {
+			continue
+		}
+		g.docChecked[p] = true
+		if oc.docComment(p.Pkg.Path(), p.Pos) == "" {
+			g.warnings = append(g.warnings, notePosition(oc.fset.Position(p.Pos),
+				fmt.Errorf("provider %s has no doc comment", p.Name)))
+		}
+	}
+}
+
+// providerDoc describes p using the declaration it came from.
+func (oc *objectCache) providerDoc(p *Provider) ProviderDoc {
+	pd := ProviderDoc{
+		ImportPath: p.Pkg.Path(),
+		Name:       p.Name,
+		Pos:        oc.fset.Position(p.Pos),
+		Doc:        oc.docComment(p.Pkg.Path(), p.Pos),
+	}
+	if obj := p.Pkg.Scope().Lookup(p.Name); obj != nil && !p.IsMethod {
+		pd.Signature = types.ObjectString(obj, types.RelativeTo(p.Pkg))
+	}
+	return pd
+}
+
+// docComment returns the text of the doc comment attached to the function or
+// type declared at pos in the package with the given import path.
+func (oc *objectCache) docComment(pkgPath string, pos token.Pos) string {
+	pkg := oc.packages[pkgPath]
+	if pkg == nil {
+		return ""
+	}
+	for _, f := range pkg.Syntax {
+		tokenFile := oc.fset.File(f.Pos())
+		if base := tokenFile.Base(); base <= int(pos) && int(pos) < base+tokenFile.Size() {
+			path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+			for _, node := range path {
+				switch node := node.(type) {
+				case *ast.FuncDecl:
+					return node.Doc.Text()
+				case *ast.TypeSpec:
+					if node.Doc != nil {
+						return node.Doc.Text()
+					}
+				case *ast.GenDecl:
+					return node.Doc.Text()
+				}
+			}
+		}
+	}
+	return ""
+}
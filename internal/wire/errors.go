@@ -32,6 +32,22 @@ func (ec *errorCollector) add(errs ...error) {
 	}
 }
 
+// appendNewErrors appends the errors in errs that are not already in dst,
+// compared by their messages.
+func appendNewErrors(dst, errs []error) []error {
+	seen := make(map[string]bool, len(dst))
+	for _, e := range dst {
+		seen[e.Error()] = true
+	}
+	for _, e := range errs {
+		if !seen[e.Error()] {
+			seen[e.Error()] = true
+			dst = append(dst, e)
+		}
+	}
+	return dst
+}
+
 // mapErrors returns a new slice that wraps any errors using the given function.
 func mapErrors(errs []error, f func(error) error) []error {
 	if len(errs) == 0 {
@@ -82,3 +98,8 @@ func (w *wireErr) Error() string {
 	}
 	return w.position.String() + ": " + w.error.Error()
 }
+
+// Unwrap returns the error without its position.
+func (w *wireErr) Unwrap() error {
+	return w.error
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/types"
+	"strconv"
+)
+
+// Graph loads the packages that match the given patterns like Load and
+// returns a Graphviz DOT document describing how each injector builds its
+// output. Each injector is drawn as a cluster with one node per injector
+// argument and per provider, labeled with the provider name and the type it
+// produces, and with an edge from each provider to the nodes that satisfy
+// its arguments.
+func Graph(ctx context.Context, wd string, env []string, injectTag, tags string, patterns []string) ([]byte, []error) {
+	info, errs := Load(ctx, wd, env, injectTag, tags, patterns)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString("digraph wire {\n")
+	for i, in := range info.Injectors {
+		writeInjectorGraph(buf, i, in, &info.solved[i])
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// writeInjectorGraph writes the cluster for the i'th injector to buf.
+func writeInjectorGraph(buf *bytes.Buffer, i int, in *Injector, s *solvedInjector) {
+	node := func(j int) string {
+		return strconv.Quote(fmt.Sprintf("%s.%s#%d", in.ImportPath, in.FuncName, j))
+	}
+	fmt.Fprintf(buf, "\tsubgraph cluster_%d {\n", i)
+	fmt.Fprintf(buf, "\t\tlabel=%s;\n", strconv.Quote(in.ImportPath+"."+in.FuncName))
+	numGiven := s.given.Len()
+	for j := 0; j < numGiven; j++ {
+		v := s.given.At(j)
+		label := fmt.Sprintf("argument %s\n%s", v.Name(), types.TypeString(v.Type(), nil))
+		fmt.Fprintf(buf, "\t\t%s [label=%s, shape=box];\n", node(j), strconv.Quote(label))
+	}
+	for j, c := range s.calls {
+		label := callLabel(&c)
+		if c.out != nil {
+			label += "\n" + types.TypeString(c.out, nil)
+		}
+		fmt.Fprintf(buf, "\t\t%s [label=%s];\n", node(numGiven+j), strconv.Quote(label))
+	}
+	for j, c := range s.calls {
+		for _, a := range c.args {
+			fmt.Fprintf(buf, "\t\t%s -> %s;\n", node(numGiven+j), node(a))
+		}
+	}
+	buf.WriteString("\t}\n")
+}
+
+// callLabel returns a short description of the provider used by c.
+func callLabel(c *call) string {
+	switch c.kind {
+	case funcProviderCall:
+		if c.method {
+			return fmt.Sprintf("(%s).%s", types.TypeString(c.ins[0], nil), c.name)
+		}
+		return c.pkg.Path() + "." + c.name
+	case structProvider:
+		return "struct " + c.pkg.Path() + "." + c.name
+	case valueExpr:
+		return "value"
+	case selectorExpr:
+		return "field " + c.name
+	case convertExpr:
+		return "conversion"
+	case addressExpr:
+		return "address"
+	case derefExpr:
+		return "dereference"
+	case collectExpr:
+		return "collection"
+	case resultExpr:
+		return fmt.Sprintf("result %d", c.result+1)
+	case invokeCall:
+		return "invoke " + c.pkg.Path() + "." + c.name
+	default:
+		panic("unknown kind")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// InjectorMetric summarizes the complexity of a single injector.
+type InjectorMetric struct {
+	// ImportPath is the import path of the package declaring the injector.
+	ImportPath string
+	// FuncName is the name of the injector function.
+	FuncName string
+	// Pos is the position of the injector function.
+	Pos token.Position
+
+	// Providers is the number of providers, values, and fields the
+	// injector calls or reads to produce its output.
+	Providers int
+	// MaxDepth is the length of the longest chain of dependencies from an
+	// injector argument or a provider without arguments to the output.
+	// An injector that only returns one of its arguments has depth 0.
+	MaxDepth int
+	// Packages is the number of distinct packages that declare providers
+	// called by the injector.
+	Packages int
+	// CanFail reports whether any provider used by the injector returns an
+	// error.
+	CanFail bool
+	// HasCleanup reports whether any provider used by the injector returns
+	// a cleanup function.
+	HasCleanup bool
+}
+
+// Metrics computes an InjectorMetric for every injector in the packages that
+// match the given patterns. The arguments are interpreted the same as for
+// Load. Injectors that have errors are reported in the returned errors and
+// omitted from the metrics.
+func Metrics(ctx context.Context, wd string, env []string, injectTag, tags string, patterns []string) ([]InjectorMetric, []error) {
+	pkgs, errs := load(ctx, wd, env, injectTag, tags, patterns)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	oc := newObjectCache(pkgs)
+	ec := new(errorCollector)
+	var metrics []InjectorMetric
+	for _, pkg := range pkgs {
+		if isWireImport(pkg.PkgPath) {
+			continue
+		}
+		for _, f := range pkg.Syntax {
+			if ignoredFile(f) {
+				continue
+			}
+			for _, decl := range f.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+				buildCall, err := findInjectorBuild(pkg.TypesInfo, fn)
+				if err != nil {
+					ec.add(notePosition(oc.fset.Position(fn.Pos()), fmt.Errorf("inject %s: %v", fn.Name.Name, err)))
+					continue
+				}
+				if buildCall == nil {
+					continue
+				}
+				sig := pkg.TypesInfo.ObjectOf(fn.Name).Type().(*types.Signature)
+				ins, out, err := injectorFuncSignature(sig)
+				if err != nil {
+					ec.add(notePosition(oc.fset.Position(fn.Pos()), fmt.Errorf("inject %s: %v", fn.Name.Name, err)))
+					continue
+				}
+				injectorArgs := &InjectorArgs{
+					Name:  fn.Name.Name,
+					Tuple: ins,
+					Pos:   fn.Pos(),
+				}
+				set, errs := oc.processNewSet(pkg.TypesInfo, pkg.PkgPath, buildCall, injectorArgs, "")
+				if len(errs) > 0 {
+					ec.add(notePositionAll(oc.fset.Position(fn.Pos()), errs)...)
+					continue
+				}
+				calls, errs := solve(oc.fset, out.out, ins, set, nil)
+				if len(errs) > 0 {
+					ec.add(notePositionAll(oc.fset.Position(fn.Pos()), errs)...)
+					continue
+				}
+				m := callMetrics(calls, ins.Len())
+				m.ImportPath = pkg.PkgPath
+				m.FuncName = fn.Name.Name
+				m.Pos = oc.fset.Position(fn.Pos())
+				metrics = append(metrics, m)
+			}
+		}
+	}
+	return metrics, ec.errors
+}
+
+// callMetrics computes the metrics of the calls returned by solve for an
+// injector with numGiven arguments.
+func callMetrics(calls []call, numGiven int) InjectorMetric {
+	var m InjectorMetric
+	pkgs := make(map[string]bool)
+	depth := make([]int, len(calls))
+	for i, c := range calls {
+		for _, a := range c.args {
+			if a >= numGiven && depth[a-numGiven] >= depth[i] {
+				depth[i] = depth[a-numGiven]
+			}
+		}
+		depth[i]++
+		if depth[i] > m.MaxDepth {
+			m.MaxDepth = depth[i]
+		}
+		if c.kind != convertExpr && c.kind != addressExpr && c.kind != collectExpr && c.kind != resultExpr && c.kind != derefExpr {
+			m.Providers++
+		}
+		if c.pkg != nil {
+			pkgs[c.pkg.Path()] = true
+		}
+		m.CanFail = m.CanFail || c.hasErr
+		m.HasCleanup = m.HasCleanup || c.hasCleanup
+	}
+	m.Packages = len(pkgs)
+	return m
+}
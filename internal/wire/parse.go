@@ -19,10 +19,12 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/token"
 	"go/types"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -40,6 +42,7 @@ type providerSetSrc struct {
 	Import      *ProviderSet
 	InjectorArg *InjectorArg
 	Field       *Field
+	Collection  *Collection
 }
 
 // description returns a string describing the source of p, including line numbers.
@@ -68,6 +71,8 @@ func (p *providerSetSrc) description(fset *token.FileSet, typ types.Type) string
 		return fmt.Sprintf("argument %s to injector function %s (%s)", args.Tuple.At(p.InjectorArg.Index).Name(), args.Name, fset.Position(args.Pos))
 	case p.Field != nil:
 		return fmt.Sprintf("wire.FieldsOf (%s)", fset.Position(p.Field.Pos))
+	case p.Collection != nil:
+		return fmt.Sprintf("wire.Collect (%s)", fset.Position(p.Collection.Pos))
 	}
 	panic("providerSetSrc with no fields set")
 }
@@ -86,6 +91,40 @@ func (p *providerSetSrc) trace(fset *token.FileSet, typ types.Type) []string {
 	return retval
 }
 
+// origin returns the source that p leads to for typ through any imported
+// sets.
+func (p *providerSetSrc) origin(typ types.Type) *providerSetSrc {
+	for p.Import != nil {
+		parent := p.Import.srcMap.At(typ)
+		if parent == nil {
+			break
+		}
+		p = parent.(*providerSetSrc)
+	}
+	return p
+}
+
+// sameOrigin reports whether p and q lead to the same source for typ, as
+// when a set is imported along two paths.
+func (p *providerSetSrc) sameOrigin(q *providerSetSrc, typ types.Type) bool {
+	p, q = p.origin(typ), q.origin(typ)
+	switch {
+	case p.Provider != nil:
+		return p.Provider == q.Provider
+	case p.Binding != nil:
+		return p.Binding == q.Binding
+	case p.Value != nil:
+		return p.Value == q.Value
+	case p.Field != nil:
+		return p.Field == q.Field
+	case p.Collection != nil:
+		return p.Collection == q.Collection
+	case p.Import != nil:
+		return p.Import == q.Import
+	}
+	return false
+}
+
 // A ProviderSet describes a set of providers.  The zero value is an empty
 // ProviderSet.
 type ProviderSet struct {
@@ -98,13 +137,33 @@ type ProviderSet struct {
 	// variable.
 	VarName string
 
-	Providers []*Provider
-	Bindings  []*IfaceBinding
-	Values    []*Value
-	Fields    []*Field
-	Imports   []*ProviderSet
+	Providers   []*Provider
+	Bindings    []*IfaceBinding
+	Values      []*Value
+	Fields      []*Field
+	Collections []*Collection
+	Imports     []*ProviderSet
 	// InjectorArgs is only filled in for wire.Build.
 	InjectorArgs *InjectorArgs
+	// Overrides lists providers that replace any other provider of the same
+	// types in the set, including the providers of imported sets. It is
+	// only filled in for wire.Build, from calls to wire.Override and from
+	// GenerateOptions.Overrides.
+	Overrides []*Provider
+	// Preferences is only filled in for wire.Build.
+	Preferences []*Preference
+	// Fallbacks are sets whose providers are only used for the types that
+	// nothing else in the set provides, as created by wire.Fallback. An
+	// earlier fallback takes precedence over a later one.
+	Fallbacks []*ProviderSet
+	// Shared lists the types whose values are shared by injectors, as
+	// declared by wire.Shared.
+	Shared []*Sharing
+	// Invocations is only filled in for wire.Build.
+	Invocations []*Invocation
+	// Generics lists the generic provider functions added with wire.Generic.
+	// They are instantiated by the injectors that need them.
+	Generics []*GenericProvider
 
 	// providerMap maps from provided type to a *ProvidedType.
 	// It includes all of the imported types.
@@ -113,6 +172,11 @@ type ProviderSet struct {
 	// srcMap maps from provided type to a *providerSetSrc capturing the
 	// Provider, Binding, Value, or Import that provided the type.
 	srcMap *typeutil.Map
+
+	// shadowed records the sources replaced by Overrides and the
+	// collections merged into another one, so that they are not reported
+	// as unused.
+	shadowed []*providerSetSrc
 }
 
 // Outputs returns a new slice containing the set of possible types the
@@ -121,6 +185,14 @@ func (set *ProviderSet) Outputs() []types.Type {
 	return set.providerMap.Keys()
 }
 
+// empty reports whether set provides nothing but the injector arguments.
+func (set *ProviderSet) empty() bool {
+	return len(set.Providers) == 0 && len(set.Bindings) == 0 && len(set.Values) == 0 &&
+		len(set.Fields) == 0 && len(set.Collections) == 0 && len(set.Imports) == 0 &&
+		len(set.Overrides) == 0 && len(set.Preferences) == 0 && len(set.Fallbacks) == 0 &&
+		len(set.Shared) == 0 && len(set.Invocations) == 0 && len(set.Generics) == 0
+}
+
 // For returns a ProvidedType for the given type, or the zero ProvidedType.
 func (set *ProviderSet) For(t types.Type) ProvidedType {
 	pt := set.providerMap.At(t)
@@ -143,6 +215,74 @@ type IfaceBinding struct {
 	Pos token.Pos
 }
 
+// A Collection describes a slice type provided by collecting the outputs of
+// several providers, declared with wire.Collect.
+type Collection struct {
+	// Pos is the position of the call to wire.Collect.
+	Pos token.Pos
+
+	// Out is the slice type the collection provides.
+	Out types.Type
+
+	// Providers produce the elements of the slice, in order. The first
+	// output of each is assignable to the slice's element type.
+	Providers []*Provider
+}
+
+// A Preference selects the provider to use when several providers in an
+// injector's provider set provide the same type.
+type Preference struct {
+	// Provider is the preferred provider.
+	Provider *Provider
+
+	// Pos is the position of the call to wire.Prefer.
+	Pos token.Pos
+}
+
+// An Invocation is a function that an injector calls for its side effects
+// before building its output, declared with wire.Invoke.
+type Invocation struct {
+	// Pkg is the package that the function resides in.
+	Pkg *types.Package
+
+	// Name is the function's name.
+	Name string
+
+	// Pos is the position of the call to wire.Invoke.
+	Pos token.Pos
+
+	// Args is the list of data dependencies the function has.
+	Args []ProviderInput
+
+	// Varargs is true if the function is variadic.
+	Varargs bool
+
+	// HasErr reports whether the function returns an error.
+	HasErr bool
+}
+
+// A Sharing marks a type whose value is built once and shared by the
+// injectors in the generated file.
+type Sharing struct {
+	// Type is the shared type.
+	Type types.Type
+
+	// Pos is the position of the call to wire.Shared.
+	Pos token.Pos
+}
+
+// A GenericProvider is a generic provider function that was added to a set
+// with wire.Generic. Its type arguments are inferred from the type an
+// injector needs.
+type GenericProvider struct {
+	// Func is the generic function.
+	Func *types.Func
+
+	// Out is the set of types the function produces, in terms of its type
+	// parameters.
+	Out []types.Type
+}
+
 // Provider records the signature of a provider. A provider is a
 // single Go object, either a function or a named struct type.
 type Provider struct {
@@ -167,7 +307,8 @@ type Provider struct {
 	IsStruct bool
 
 	// Out is the set of types this provider produces. It will always
-	// contain at least one type.
+	// contain at least one type. For a function that returns several
+	// values, Out lists their types in order.
 	Out []types.Type
 
 	// HasCleanup reports whether the provider function returns a cleanup
@@ -177,6 +318,19 @@ type Provider struct {
 	// HasErr reports whether the provider function can return an error.
 	// (Always false for structs.)
 	HasErr bool
+
+	// ErrType is the type of the error that the provider function returns
+	// when it is not error itself, but another type that implements error.
+	ErrType types.Type
+
+	// TypeArgs is the list of type arguments that a generic provider
+	// function is instantiated with, as in NewCache[string]. It is empty
+	// for non-generic functions and for structs.
+	TypeArgs []types.Type
+
+	// IsMethod is true if this provider is a method expression, as in
+	// (*Store).Session. The first element of Args is then the receiver.
+	IsMethod bool
 }
 
 // ProviderInput describes an incoming edge in the provider graph.
@@ -248,8 +402,11 @@ type Field struct {
 // env is nil or empty, it is interpreted as an empty set of variables.
 // In case of duplicate environment variables, the last one in the list
 // takes precedence.
-func Load(ctx context.Context, wd string, env []string, tags string, patterns []string) (*Info, []error) {
-	pkgs, errs := load(ctx, wd, env, tags, patterns)
+//
+// injectTag is the build tag of the files that declare injectors. If it is
+// empty, "wireinject" is used.
+func Load(ctx context.Context, wd string, env []string, injectTag, tags string, patterns []string) (*Info, []error) {
+	pkgs, errs := load(ctx, wd, env, injectTag, tags, patterns)
 	if len(errs) > 0 {
 		return nil, errs
 	}
@@ -286,6 +443,9 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 			info.Sets[id] = pset
 		}
 		for _, f := range pkg.Syntax {
+			if ignoredFile(f) {
+				continue
+			}
 			for _, decl := range f.Decls {
 				fn, ok := decl.(*ast.FuncDecl)
 				if !ok {
@@ -319,7 +479,7 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 					ec.add(notePositionAll(fset.Position(fn.Pos()), errs)...)
 					continue
 				}
-				_, errs = solve(fset, out.out, ins, set)
+				calls, errs := solve(fset, out.out, ins, set, nil)
 				if len(errs) > 0 {
 					ec.add(mapErrors(errs, func(e error) error {
 						if w, ok := e.(*wireErr); ok {
@@ -333,12 +493,17 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 					ImportPath: pkg.PkgPath,
 					FuncName:   fn.Name.Name,
 				})
+				info.solved = append(info.solved, solvedInjector{given: ins, calls: calls})
 			}
 		}
 	}
 	return info, ec.errors
 }
 
+// defaultInjectTag is the build tag of the files that declare injectors,
+// unless another one is given, as with GenerateOptions.InjectTag.
+const defaultInjectTag = "wireinject"
+
 // load typechecks the packages that match the given patterns and
 // includes source for all transitive dependencies. The patterns are
 // defined by the underlying build system. For the go tool, this is
@@ -349,17 +514,29 @@ func Load(ctx context.Context, wd string, env []string, tags string, patterns []
 // env is nil or empty, it is interpreted as an empty set of variables.
 // In case of duplicate environment variables, the last one in the list
 // takes precedence.
-func load(ctx context.Context, wd string, env []string, tags string, patterns []string) ([]*packages.Package, []error) {
+//
+// injectTag is the build tag of the files that declare injectors, or the
+// empty string for defaultInjectTag.
+func load(ctx context.Context, wd string, env []string, injectTag, tags string, patterns []string) ([]*packages.Package, []error) {
+	if injectTag == "" {
+		injectTag = defaultInjectTag
+	}
+	expr, err := constraint.Parse("//go:build " + injectTag)
+	if _, ok := expr.(*constraint.TagExpr); err != nil || !ok {
+		return nil, []error{fmt.Errorf("inject tag %q is not a valid build tag", injectTag)}
+	}
 	cfg := &packages.Config{
 		Context:    ctx,
-		Mode:       packages.LoadAllSyntax,
+		Mode:       packages.LoadAllSyntax | packages.NeedModule,
 		Dir:        wd,
 		Env:        env,
-		BuildFlags: []string{"-tags=wireinject"},
+		BuildFlags: []string{"-tags=" + injectTag},
 		// TODO(light): Use ParseFile to skip function bodies and comments in indirect packages.
 	}
-	if len(tags) > 0 {
-		cfg.BuildFlags[0] += " " + tags
+	// The tags may be separated by commas or spaces, but the go command
+	// only accepts one separator in a list.
+	for _, tag := range strings.FieldsFunc(tags, func(r rune) bool { return r == ',' || r == ' ' }) {
+		cfg.BuildFlags[0] += "," + tag
 	}
 	escaped := make([]string, len(patterns))
 	for i := range patterns {
@@ -376,11 +553,61 @@ func load(ctx context.Context, wd string, env []string, tags string, patterns []
 		}
 	}
 	if len(errs) > 0 {
-		return nil, errs
+		// The errors may come from a broken dependency, which is listed
+		// with the import that pulled it in.
+		return nil, appendNewErrors(errs, dependencyErrors(pkgs))
 	}
 	return pkgs, nil
 }
 
+// dependencyErrors returns the errors of the packages that pkgs import
+// directly or indirectly. Each error notes the import that first pulled in
+// the package, so that a broken package is attributable to the package
+// that uses it.
+func dependencyErrors(pkgs []*packages.Package) []error {
+	seen := make(map[*packages.Package]bool)
+	for _, p := range pkgs {
+		seen[p] = true
+	}
+	var errs []error
+	var visit func(p *packages.Package)
+	visit = func(p *packages.Package) {
+		paths := make([]string, 0, len(p.Imports))
+		for path := range p.Imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			dep := p.Imports[path]
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			for _, e := range dep.Errors {
+				errs = append(errs, fmt.Errorf("%v (imported by %s%s)", e, p.PkgPath, importPosition(p, path)))
+			}
+			visit(dep)
+		}
+	}
+	for _, p := range pkgs {
+		visit(p)
+	}
+	return errs
+}
+
+// importPosition returns " at " followed by the position of the first
+// import of path in p, or the empty string if it is not found.
+func importPosition(p *packages.Package, path string) string {
+	for _, f := range p.Syntax {
+		for _, imp := range f.Imports {
+			if v, err := strconv.Unquote(imp.Path.Value); err == nil && v == path {
+				return " at " + p.Fset.Position(imp.Pos()).String()
+			}
+		}
+	}
+	return ""
+}
+
 // Info holds the result of Load.
 type Info struct {
 	Fset *token.FileSet
@@ -391,6 +618,17 @@ type Info struct {
 	// Injectors contains all the injector functions in the initial packages.
 	// The order is undefined.
 	Injectors []*Injector
+
+	// solved holds the steps of each injector in Injectors, in the same
+	// order.
+	solved []solvedInjector
+}
+
+// solvedInjector is an injector's parameters and the steps that produce its
+// output, as found by solve.
+type solvedInjector struct {
+	given *types.Tuple
+	calls []call
 }
 
 // A ProviderSetID identifies a named provider set.
@@ -421,11 +659,16 @@ type objectCache struct {
 	packages map[string]*packages.Package
 	objects  map[objRef]objCacheEntry
 	hasher   typeutil.Hasher
+	// strictBindings makes an interface binding conflict with a provider
+	// of the interface type instead of giving way to it.
+	strictBindings bool
 }
 
 type objRef struct {
 	importPath string
 	name       string
+	// typeArgs identifies the instantiation of a generic function.
+	typeArgs string
 }
 
 type objCacheEntry struct {
@@ -433,6 +676,23 @@ type objCacheEntry struct {
 	errs []error
 }
 
+// providerSetErrors returns the errors in the package-level provider sets of
+// pkg.
+func providerSetErrors(oc *objectCache, pkg *packages.Package) []error {
+	ec := new(errorCollector)
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !isProviderSetType(obj.Type()) {
+			continue
+		}
+		if _, errs := oc.get(obj); len(errs) > 0 {
+			ec.add(notePositionAll(pkg.Fset.Position(obj.Pos()), errs)...)
+		}
+	}
+	return ec.errors
+}
+
 func newObjectCache(pkgs []*packages.Package) *objectCache {
 	if len(pkgs) == 0 {
 		panic("object cache must have packages to draw from")
@@ -462,6 +722,19 @@ func newObjectCache(pkgs []*packages.Package) *objectCache {
 	return oc
 }
 
+// forPackage returns a cache of pkg and its dependencies that shares the
+// objects processed so far with oc, so that a provider set used by several
+// packages is only processed once. pkg must come from the same call to
+// packages.Load as the packages of oc, since objects are identified by
+// import path and name.
+func (oc *objectCache) forPackage(pkg *packages.Package) *objectCache {
+	c := newObjectCache([]*packages.Package{pkg})
+	c.objects = oc.objects
+	c.hasher = oc.hasher
+	c.strictBindings = oc.strictBindings
+	return c
+}
+
 // get converts a Go object into a Wire structure. It may return a *Provider, an
 // *IfaceBinding, a *ProviderSet, a *Value, or a []*Field.
 func (oc *objectCache) get(obj types.Object) (val interface{}, errs []error) {
@@ -499,6 +772,26 @@ func (oc *objectCache) get(obj types.Object) (val interface{}, errs []error) {
 	}
 }
 
+// getInstance converts an instantiation of a generic function into a
+// provider.
+func (oc *objectCache) getInstance(fn *types.Func, inst types.Instance) (*Provider, []error) {
+	ref := objRef{
+		importPath: fn.Pkg().Path(),
+		name:       fn.Name(),
+		typeArgs:   typeListString(inst.TypeArgs),
+	}
+	if ent, cached := oc.objects[ref]; cached {
+		p, _ := ent.val.(*Provider)
+		return p, append([]error(nil), ent.errs...)
+	}
+	p, errs := processFuncInstanceProvider(oc.fset, fn, inst.Type.(*types.Signature), typeListSlice(inst.TypeArgs))
+	oc.objects[ref] = objCacheEntry{
+		val:  p,
+		errs: append([]error(nil), errs...),
+	}
+	return p, errs
+}
+
 // varDecl finds the declaration that defines the given variable.
 func (oc *objectCache) varDecl(obj *types.Var) *ast.ValueSpec {
 	// TODO(light): Walk files to build object -> declaration mapping, if more performant.
@@ -530,6 +823,20 @@ func (oc *objectCache) processExpr(info *types.Info, pkgPath string, expr ast.Ex
 			return notePosition(exprPos, err)
 		})
 	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if s := info.Selections[sel]; s != nil && s.Kind() == types.MethodExpr {
+			item, errs := oc.getMethod(s)
+			return item, mapErrors(errs, func(err error) error {
+				return notePosition(exprPos, err)
+			})
+		}
+	}
+	if fn, inst, ok := funcInstance(info, expr); ok {
+		item, errs := oc.getInstance(fn, inst)
+		return item, mapErrors(errs, func(err error) error {
+			return notePosition(exprPos, err)
+		})
+	}
 	if call, ok := expr.(*ast.CallExpr); ok {
 		fnObj := qualifiedIdentObject(info, call.Fun)
 		if fnObj == nil {
@@ -576,6 +883,38 @@ func (oc *objectCache) processExpr(info *types.Info, pkgPath string, expr ast.Ex
 				return nil, []error{notePosition(exprPos, err)}
 			}
 			return v, nil
+		case "Prefer":
+			pref, errs := oc.processPrefer(info, call)
+			return pref, notePositionAll(exprPos, errs)
+		case "Override":
+			o, errs := oc.processOverride(info, call)
+			return o, notePositionAll(exprPos, errs)
+		case "Generic":
+			g, errs := processGenericProvider(oc.fset, info, call)
+			return g, notePositionAll(exprPos, errs)
+		case "Collect":
+			c, errs := oc.processCollect(info, pkgPath, call)
+			return c, notePositionAll(exprPos, errs)
+		case "Fallback":
+			pset, errs := oc.processFallback(info, pkgPath, call, varName)
+			return pset, notePositionAll(exprPos, errs)
+		case "Shared":
+			sh, err := processShared(oc.fset, info, call)
+			if err != nil {
+				return nil, []error{notePosition(exprPos, err)}
+			}
+			return sh, nil
+		case "Invoke":
+			inv, err := processInvoke(oc.fset, info, call)
+			if err != nil {
+				return nil, []error{notePosition(exprPos, err)}
+			}
+			return inv, nil
+		case "PackageSets":
+			if len(call.Args) != 0 {
+				return nil, []error{notePosition(exprPos, errors.New("call to PackageSets takes no arguments"))}
+			}
+			return packageSets{pos: call.Pos()}, nil
 		default:
 			return nil, []error{notePosition(exprPos, errors.New("unknown pattern"))}
 		}
@@ -609,6 +948,8 @@ func (oc *objectCache) processNewSet(info *types.Info, pkgPath string, call *ast
 		switch item := item.(type) {
 		case *Provider:
 			pset.Providers = append(pset.Providers, item)
+		case *GenericProvider:
+			pset.Generics = append(pset.Generics, item)
 		case *ProviderSet:
 			pset.Imports = append(pset.Imports, item)
 		case *IfaceBinding:
@@ -617,6 +958,39 @@ func (oc *objectCache) processNewSet(info *types.Info, pkgPath string, call *ast
 			pset.Values = append(pset.Values, item)
 		case []*Field:
 			pset.Fields = append(pset.Fields, item...)
+		case *Collection:
+			pset.Collections = append(pset.Collections, item)
+		case *Preference:
+			if args == nil {
+				ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("wire.Prefer may only be used in wire.Build")))
+				continue
+			}
+			pset.Preferences = append(pset.Preferences, item)
+		case override:
+			if args == nil {
+				ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("wire.Override may only be used in wire.Build")))
+				continue
+			}
+			pset.Overrides = append(pset.Overrides, item.provider)
+		case *Sharing:
+			pset.Shared = append(pset.Shared, item)
+		case *Invocation:
+			if args == nil {
+				ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("wire.Invoke may only be used in wire.Build")))
+				continue
+			}
+			pset.Invocations = append(pset.Invocations, item)
+		case packageSets:
+			if args == nil {
+				ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("wire.PackageSets may only be used in wire.Build")))
+				continue
+			}
+			set, errs := oc.processPackageSets(pkgPath, item.pos)
+			if len(errs) > 0 {
+				ec.add(errs...)
+				continue
+			}
+			pset.Imports = append(pset.Imports, set)
 		default:
 			panic("unknown item type")
 		}
@@ -625,16 +999,144 @@ func (oc *objectCache) processNewSet(info *types.Info, pkgPath string, call *ast
 		return nil, ec.errors
 	}
 	var errs []error
-	pset.providerMap, pset.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, pset)
+	pset.providerMap, pset.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, pset, oc.strictBindings)
 	if len(errs) > 0 {
 		return nil, errs
 	}
-	if errs := verifyAcyclic(pset.providerMap, oc.hasher); len(errs) > 0 {
+	if errs := verifyAcyclic(oc.fset, pset.providerMap, oc.hasher); len(errs) > 0 {
+		return nil, errs
+	}
+	return pset, nil
+}
+
+// packageSets is the result of a call to wire.PackageSets, which is
+// resolved by processNewSet.
+type packageSets struct {
+	pos token.Pos
+}
+
+// processPackageSets combines the package-level provider sets of the package
+// with the given import path for a call to wire.PackageSets at pos.
+func (oc *objectCache) processPackageSets(pkgPath string, pos token.Pos) (*ProviderSet, []error) {
+	pset := &ProviderSet{
+		Pos:     pos,
+		PkgPath: pkgPath,
+	}
+	scope := oc.packages[pkgPath].Types.Scope()
+	ec := new(errorCollector)
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.Var)
+		if !ok || !isProviderSetType(obj.Type()) {
+			continue
+		}
+		item, errs := oc.get(obj)
+		if len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		}
+		pset.Imports = append(pset.Imports, item.(*ProviderSet))
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	if len(pset.Imports) == 0 {
+		return nil, []error{notePosition(oc.fset.Position(pos), fmt.Errorf("wire.PackageSets: package %s declares no provider sets", pkgPath))}
+	}
+	var errs []error
+	pset.providerMap, pset.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, pset, oc.strictBindings)
+	if len(errs) > 0 {
+		return nil, append(errs, notePosition(oc.fset.Position(pos),
+			errors.New("wire.PackageSets: the provider sets of the package conflict; pass the sets to use to wire.Build instead")))
+	}
+	if errs := verifyAcyclic(oc.fset, pset.providerMap, oc.hasher); len(errs) > 0 {
 		return nil, errs
 	}
 	return pset, nil
 }
 
+// processFallback creates a provider set from a call to wire.Fallback. The
+// first argument is imported as usual, and the rest are fallbacks.
+func (oc *objectCache) processFallback(info *types.Info, pkgPath string, call *ast.CallExpr, varName string) (*ProviderSet, []error) {
+	// Assumes that call.Fun is wire.Fallback.
+
+	if len(call.Args) < 2 {
+		return nil, []error{errors.New("call to Fallback takes at least two provider sets")}
+	}
+	pset := &ProviderSet{
+		Pos:     call.Pos(),
+		PkgPath: pkgPath,
+		VarName: varName,
+	}
+	ec := new(errorCollector)
+	for i, arg := range call.Args {
+		item, errs := oc.processExpr(info, pkgPath, arg, "")
+		if len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		}
+		set, ok := item.(*ProviderSet)
+		if !ok {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("arguments to Fallback must be provider sets")))
+			continue
+		}
+		if i == 0 {
+			pset.Imports = append(pset.Imports, set)
+		} else {
+			pset.Fallbacks = append(pset.Fallbacks, set)
+		}
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	var errs []error
+	pset.providerMap, pset.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, pset, oc.strictBindings)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if errs := verifyAcyclic(oc.fset, pset.providerMap, oc.hasher); len(errs) > 0 {
+		return nil, errs
+	}
+	return pset, nil
+}
+
+// resolveOverrides finds the provider functions named by overrides in the
+// cache's packages.
+func (oc *objectCache) resolveOverrides(overrides []ProviderOverride) ([]*Provider, []error) {
+	ec := new(errorCollector)
+	var providers []*Provider
+	for _, o := range overrides {
+		pkg := oc.packages[o.ImportPath]
+		if pkg == nil {
+			ec.add(fmt.Errorf("override %s.%s: package %q is not a dependency of the generated package", o.ImportPath, o.FuncName, o.ImportPath))
+			continue
+		}
+		fn, ok := pkg.Types.Scope().Lookup(o.FuncName).(*types.Func)
+		if !ok {
+			ec.add(fmt.Errorf("override %s.%s: no function named %s in package %q", o.ImportPath, o.FuncName, o.FuncName, o.ImportPath))
+			continue
+		}
+		item, errs := oc.get(fn)
+		if len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		}
+		providers = append(providers, item.(*Provider))
+	}
+	return providers, ec.errors
+}
+
+// applyOverrides adds overrides to an injector's provider set and rebuilds
+// its provider map, which also picks up any imports added to the set.
+func (oc *objectCache) applyOverrides(set *ProviderSet, overrides []*Provider) []error {
+	set.Overrides = append(set.Overrides[:len(set.Overrides):len(set.Overrides)], overrides...)
+	var errs []error
+	set.providerMap, set.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, set, oc.strictBindings)
+	if len(errs) > 0 {
+		return errs
+	}
+	return verifyAcyclic(oc.fset, set.providerMap, oc.hasher)
+}
+
 // structArgType attempts to interpret an expression as a simple struct type.
 // It assumes any parentheses have been stripped.
 func structArgType(info *types.Info, expr ast.Expr) *types.TypeName {
@@ -672,12 +1174,133 @@ func qualifiedIdentObject(info *types.Info, expr ast.Expr) types.Object {
 	}
 }
 
+// getMethod returns the provider for the method expression sel, as in
+// (*Store).Session.
+func (oc *objectCache) getMethod(sel *types.Selection) (*Provider, []error) {
+	fn := sel.Obj().(*types.Func)
+	ref := objRef{
+		importPath: fn.Pkg().Path(),
+		name:       "(" + types.TypeString(sel.Recv(), nil) + ")." + fn.Name(),
+	}
+	if ent, cached := oc.objects[ref]; cached {
+		p, _ := ent.val.(*Provider)
+		return p, append([]error(nil), ent.errs...)
+	}
+	p, errs := processMethodProvider(oc.fset, fn, sel)
+	oc.objects[ref] = objCacheEntry{
+		val:  p,
+		errs: append([]error(nil), errs...),
+	}
+	return p, errs
+}
+
+// funcInstance returns the generic function and its instance for an
+// explicit instantiation like NewCache[string] or pkg.NewMap[string, int].
+func funcInstance(info *types.Info, expr ast.Expr) (*types.Func, types.Instance, bool) {
+	var x ast.Expr
+	switch expr := expr.(type) {
+	case *ast.IndexExpr:
+		x = expr.X
+	case *ast.IndexListExpr:
+		x = expr.X
+	default:
+		return nil, types.Instance{}, false
+	}
+	fn, ok := qualifiedIdentObject(info, x).(*types.Func)
+	if !ok {
+		return nil, types.Instance{}, false
+	}
+	var ident *ast.Ident
+	switch x := x.(type) {
+	case *ast.Ident:
+		ident = x
+	case *ast.SelectorExpr:
+		ident = x.Sel
+	}
+	inst, ok := info.Instances[ident]
+	return fn, inst, ok
+}
+
+// typeListSlice returns the types in list.
+func typeListSlice(list *types.TypeList) []types.Type {
+	s := make([]types.Type, list.Len())
+	for i := range s {
+		s[i] = list.At(i)
+	}
+	return s
+}
+
+// typeListString returns the type arguments in list separated by commas.
+func typeListString(list *types.TypeList) string {
+	args := make([]string, list.Len())
+	for i := range args {
+		args[i] = types.TypeString(list.At(i), nil)
+	}
+	return strings.Join(args, ", ")
+}
+
 // processFuncProvider creates a provider for a function declaration.
 func processFuncProvider(fset *token.FileSet, fn *types.Func) (*Provider, []error) {
 	sig := fn.Type().(*types.Signature)
+	if sig.TypeParams().Len() > 0 {
+		return nil, []error{notePosition(fset.Position(fn.Pos()), fmt.Errorf("provider %s is generic; instantiate it with type arguments, as in %s[T]", fn.Name(), fn.Name()))}
+	}
+	return newFuncProvider(fset, fn, sig)
+}
+
+// processFuncInstanceProvider creates a provider for an instantiation of a
+// generic function, given its instantiated signature and type arguments.
+func processFuncInstanceProvider(fset *token.FileSet, fn *types.Func, sig *types.Signature, typeArgs []types.Type) (*Provider, []error) {
+	provider, errs := newFuncProvider(fset, fn, sig)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	provider.TypeArgs = typeArgs
+	return provider, nil
+}
+
+// processGenericProvider creates a generic provider from a wire.Generic
+// call. The type arguments of its argument are ignored.
+func processGenericProvider(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*GenericProvider, []error) {
+	// Assumes that call.Fun is wire.Generic.
+
+	if len(call.Args) != 1 {
+		return nil, []error{errors.New("call to Generic takes exactly one argument")}
+	}
+	fn, _, ok := funcInstance(info, astutil.Unparen(call.Args[0]))
+	if !ok {
+		return nil, []error{errors.New("argument to Generic must be an instantiation of a generic provider function, as in NewCache[any]")}
+	}
+	provider, errs := newFuncProvider(fset, fn, fn.Type().(*types.Signature))
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &GenericProvider{Func: fn, Out: provider.Out}, nil
+}
+
+// processMethodProvider creates a provider for the method expression sel
+// of the method fn. The receiver is the provider's first argument, so the
+// value it is called on comes from the provider graph like any other input.
+func processMethodProvider(fset *token.FileSet, fn *types.Func, sel *types.Selection) (*Provider, []error) {
+	provider, errs := newFuncProvider(fset, fn, sel.Type().(*types.Signature))
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	provider.IsMethod = true
+	return provider, nil
+}
+
+// newFuncProvider creates a provider for a function with the given
+// signature, which is the instantiated signature for a generic function.
+func newFuncProvider(fset *token.FileSet, fn *types.Func, sig *types.Signature) (*Provider, []error) {
 	fpos := fn.Pos()
-	providerSig, err := funcOutput(sig)
-	if err != nil {
+	out := make([]types.Type, 1)
+	providerSig, errType, err := providerOutput(sig)
+	if err == nil {
+		out[0] = providerSig.out
+	} else if tuple, hasErr, ok := tupleOutput(sig); ok {
+		out, providerSig = tuple, outputSignature{out: tuple[0], err: hasErr}
+	} else {
 		return nil, []error{notePosition(fset.Position(fpos), fmt.Errorf("wrong signature for provider %s: %v", fn.Name(), err))}
 	}
 	params := sig.Params()
@@ -687,9 +1310,10 @@ func processFuncProvider(fset *token.FileSet, fn *types.Func) (*Provider, []erro
 		Pos:        fn.Pos(),
 		Args:       make([]ProviderInput, params.Len()),
 		Varargs:    sig.Variadic(),
-		Out:        []types.Type{providerSig.out},
+		Out:        out,
 		HasCleanup: providerSig.cleanup,
 		HasErr:     providerSig.err,
+		ErrType:    errType,
 	}
 	for i := 0; i < params.Len(); i++ {
 		provider.Args[i] = ProviderInput{
@@ -753,6 +1377,62 @@ func funcOutput(sig *types.Signature) (outputSignature, error) {
 	}
 }
 
+// providerOutput validates a provider function's return signature. Unlike an
+// injector, a provider may return another type that implements error in
+// place of error, which is returned as errType.
+func providerOutput(sig *types.Signature) (out outputSignature, errType types.Type, err error) {
+	results := sig.Results()
+	n := results.Len()
+	if n != 2 && n != 3 {
+		out, err = funcOutput(sig)
+		return out, nil, err
+	}
+	last := results.At(n - 1)
+	if types.Identical(last.Type(), errorType) || !types.Implements(last.Type(), errorType.Underlying().(*types.Interface)) {
+		out, err = funcOutput(sig)
+		return out, nil, err
+	}
+	// Check the other results as if the function returned error.
+	vars := make([]*types.Var, n)
+	for i := range vars {
+		vars[i] = results.At(i)
+	}
+	vars[n-1] = types.NewVar(last.Pos(), last.Pkg(), last.Name(), errorType)
+	out, err = funcOutput(types.NewSignatureType(nil, nil, nil, sig.Params(), types.NewTuple(vars...), sig.Variadic()))
+	if err != nil {
+		return outputSignature{}, nil, err
+	}
+	return out, last.Type(), nil
+}
+
+// tupleOutput reports whether sig returns two or more values of distinct
+// types, optionally followed by an error, and returns the types of the
+// values. Such a provider produces each of the types with a single call.
+func tupleOutput(sig *types.Signature) (out []types.Type, hasErr bool, ok bool) {
+	results := sig.Results()
+	n := results.Len()
+	if n > 0 && types.Identical(results.At(n-1).Type(), errorType) {
+		n--
+		hasErr = true
+	}
+	if n < 2 {
+		return nil, false, false
+	}
+	for i := 0; i < n; i++ {
+		t := results.At(i).Type()
+		if types.Identical(t, errorType) || types.Identical(t, cleanupType) {
+			return nil, false, false
+		}
+		for _, u := range out {
+			if types.Identical(t, u) {
+				return nil, false, false
+			}
+		}
+		out = append(out, t)
+	}
+	return out, hasErr, true
+}
+
 // processStructLiteralProvider creates a provider for a named struct type.
 // It produces pointer and non-pointer variants via two values in Out.
 //
@@ -880,6 +1560,64 @@ func isPrevented(tag string) bool {
 	return reflect.StructTag(tag).Get("wire") == "-"
 }
 
+// processShared creates a sharing from a wire.Shared call.
+func processShared(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*Sharing, error) {
+	// Assumes that call.Fun is wire.Shared.
+
+	if len(call.Args) != 1 {
+		return nil, notePosition(fset.Position(call.Pos()), errors.New("call to Shared takes exactly one argument"))
+	}
+	ptr, ok := info.TypeOf(call.Args[0]).(*types.Pointer)
+	if !ok {
+		return nil, notePosition(fset.Position(call.Pos()),
+			fmt.Errorf("argument to Shared must be a pointer; found %s", types.TypeString(info.TypeOf(call.Args[0]), nil)))
+	}
+	return &Sharing{
+		Type: ptr.Elem(),
+		Pos:  call.Pos(),
+	}, nil
+}
+
+// processInvoke creates an invocation from a wire.Invoke call.
+func processInvoke(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*Invocation, error) {
+	// Assumes that call.Fun is wire.Invoke.
+
+	if len(call.Args) != 1 {
+		return nil, errors.New("call to Invoke takes exactly one argument")
+	}
+	fn, ok := qualifiedIdentObject(info, astutil.Unparen(call.Args[0])).(*types.Func)
+	if !ok {
+		return nil, errors.New("argument to Invoke must be a function")
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() != nil || sig.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("argument to Invoke must be a non-generic function; found %s", fn.Name())
+	}
+	inv := &Invocation{
+		Pkg:     fn.Pkg(),
+		Name:    fn.Name(),
+		Pos:     call.Pos(),
+		Varargs: sig.Variadic(),
+	}
+	switch results := sig.Results(); {
+	case results.Len() == 1 && types.Identical(results.At(0).Type(), errorType):
+		inv.HasErr = true
+	case results.Len() != 0:
+		return nil, notePosition(fset.Position(fn.Pos()), fmt.Errorf("wrong signature for %s: an invoked function must return nothing or only an error", fn.Name()))
+	}
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		t := params.At(i).Type()
+		for _, prev := range inv.Args {
+			if types.Identical(t, prev.Type) {
+				return nil, notePosition(fset.Position(fn.Pos()), fmt.Errorf("%s has multiple parameters of type %s", fn.Name(), types.TypeString(t, nil)))
+			}
+		}
+		inv.Args = append(inv.Args, ProviderInput{Type: t})
+	}
+	return inv, nil
+}
+
 // processBind creates an interface binding from a wire.Bind call.
 func processBind(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*IfaceBinding, error) {
 	// Assumes that call.Fun is wire.Bind.
@@ -916,8 +1654,7 @@ func processBind(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*If
 			errors.New("cannot bind interface to itself"))
 	}
 	if !types.Implements(provided, methodSet) {
-		return nil, notePosition(fset.Position(call.Pos()),
-			fmt.Errorf("%s does not implement %s", types.TypeString(provided, nil), types.TypeString(iface, nil)))
+		return nil, notePosition(fset.Position(call.Pos()), notImplementedError(provided, iface, methodSet))
 	}
 	return &IfaceBinding{
 		Pos:      call.Pos(),
@@ -926,6 +1663,112 @@ func processBind(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*If
 	}, nil
 }
 
+// notImplementedError returns the error for a type t that does not
+// implement iface, whose underlying type is methodSet. If a pointer to t
+// would implement iface, the error names the method with a pointer receiver.
+func notImplementedError(t, iface types.Type, methodSet *types.Interface) error {
+	ts, is := types.TypeString(t, nil), types.TypeString(iface, nil)
+	if _, isPtr := t.Underlying().(*types.Pointer); !isPtr && !types.IsInterface(t) && types.Implements(types.NewPointer(t), methodSet) {
+		if m, _ := types.MissingMethod(t, methodSet, true); m != nil {
+			return fmt.Errorf("%s does not implement %s: method %s has a pointer receiver, so only *%s implements it", ts, is, m.Name(), ts)
+		}
+	}
+	return fmt.Errorf("%s does not implement %s", ts, is)
+}
+
+// processPrefer creates a preference from a wire.Prefer call.
+func (oc *objectCache) processPrefer(info *types.Info, call *ast.CallExpr) (*Preference, []error) {
+	// Assumes that call.Fun is wire.Prefer.
+
+	if len(call.Args) != 1 {
+		return nil, []error{errors.New("call to Prefer takes exactly one argument")}
+	}
+	fn, ok := qualifiedIdentObject(info, astutil.Unparen(call.Args[0])).(*types.Func)
+	if !ok {
+		return nil, []error{errors.New("argument to Prefer must be a provider function")}
+	}
+	item, errs := oc.get(fn)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &Preference{Provider: item.(*Provider), Pos: call.Pos()}, nil
+}
+
+// override is the result of a call to wire.Override, which is added to the
+// overrides of the injector's provider set by processNewSet.
+type override struct {
+	provider *Provider
+}
+
+// processOverride creates an override from a wire.Override call.
+func (oc *objectCache) processOverride(info *types.Info, call *ast.CallExpr) (override, []error) {
+	// Assumes that call.Fun is wire.Override.
+
+	if len(call.Args) != 1 {
+		return override{}, []error{errors.New("call to Override takes exactly one argument")}
+	}
+	fn, ok := qualifiedIdentObject(info, astutil.Unparen(call.Args[0])).(*types.Func)
+	if !ok {
+		return override{}, []error{errors.New("argument to Override must be a provider function")}
+	}
+	item, errs := oc.get(fn)
+	if len(errs) > 0 {
+		return override{}, errs
+	}
+	return override{provider: item.(*Provider)}, nil
+}
+
+// processCollect creates a collection from a wire.Collect call.
+func (oc *objectCache) processCollect(info *types.Info, pkgPath string, call *ast.CallExpr) (*Collection, []error) {
+	// Assumes that call.Fun is wire.Collect.
+
+	if len(call.Args) < 2 {
+		return nil, []error{errors.New("call to Collect takes a slice type and at least one provider")}
+	}
+	ptr, ok := info.TypeOf(call.Args[0]).(*types.Pointer)
+	if !ok {
+		return nil, []error{errors.New("first argument to Collect must be a pointer to a slice type")}
+	}
+	slice, ok := ptr.Elem().Underlying().(*types.Slice)
+	if !ok {
+		return nil, []error{fmt.Errorf("first argument to Collect must be a pointer to a slice type; found %s", types.TypeString(ptr, nil))}
+	}
+	c := &Collection{
+		Pos: call.Pos(),
+		Out: ptr.Elem(),
+	}
+	ec := new(errorCollector)
+	for _, arg := range call.Args[1:] {
+		item, errs := oc.processExpr(info, pkgPath, arg, "")
+		if len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		}
+		if g, ok := item.(*GenericProvider); ok {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()), fmt.Errorf("wire.Generic(%s) cannot be collected; instantiate it with the element type instead", g.Func.Name())))
+			continue
+		}
+		p, ok := item.(*Provider)
+		if !ok {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()), errors.New("arguments to Collect after the slice type must be providers")))
+			continue
+		}
+		if len(p.Out) > 1 && !p.IsStruct {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()), fmt.Errorf("provider %s returns more than one value and cannot be collected", p.Name)))
+			continue
+		}
+		if !types.AssignableTo(p.Out[0], slice.Elem()) {
+			ec.add(notePosition(oc.fset.Position(arg.Pos()), fmt.Errorf("provider %s returns %s, which cannot be collected into %s", p.Name, types.TypeString(p.Out[0], nil), types.TypeString(c.Out, nil))))
+			continue
+		}
+		c.Providers = append(c.Providers, p)
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	return c, nil
+}
+
 // processValue creates a value from a wire.Value call.
 func processValue(fset *token.FileSet, info *types.Info, call *ast.CallExpr) (*Value, error) {
 	// Assumes that call.Fun is wire.Value.
@@ -990,7 +1833,7 @@ func processInterfaceValue(fset *token.FileSet, info *types.Info, call *ast.Call
 	}
 	provided := info.TypeOf(call.Args[1])
 	if !types.Implements(provided, methodSet) {
-		return nil, notePosition(fset.Position(call.Pos()), fmt.Errorf("%s does not implement %s", types.TypeString(provided, nil), types.TypeString(iface, nil)))
+		return nil, notePosition(fset.Position(call.Pos()), notImplementedError(provided, iface, methodSet))
 	}
 	return &Value{
 		Pos:  call.Args[1].Pos(),
@@ -1162,11 +2005,12 @@ type ProvidedType struct {
 	v *Value
 	a *InjectorArg
 	f *Field
+	c *Collection
 }
 
 // IsNil reports whether pt is the zero value.
 func (pt ProvidedType) IsNil() bool {
-	return pt.p == nil && pt.v == nil && pt.a == nil && pt.f == nil
+	return pt.p == nil && pt.v == nil && pt.a == nil && pt.f == nil && pt.c == nil
 }
 
 // Type returns the output type.
@@ -1176,6 +2020,7 @@ func (pt ProvidedType) IsNil() bool {
 //     whose element type is the struct type.
 //   - For a value, this is the type of the expression.
 //   - For an argument, this is the type of the argument.
+//   - For a collection, this is the slice type.
 func (pt ProvidedType) Type() types.Type {
 	return pt.t
 }
@@ -1200,6 +2045,11 @@ func (pt ProvidedType) IsField() bool {
 	return pt.f != nil
 }
 
+// IsCollection reports whether pt points to a Collection.
+func (pt ProvidedType) IsCollection() bool {
+	return pt.c != nil
+}
+
 // Provider returns pt as a Provider pointer. It panics if pt does not point
 // to a Provider.
 func (pt ProvidedType) Provider() *Provider {
@@ -1236,6 +2086,15 @@ func (pt ProvidedType) Field() *Field {
 	return pt.f
 }
 
+// Collection returns pt as a Collection pointer. It panics if pt does not
+// point to a Collection.
+func (pt ProvidedType) Collection() *Collection {
+	if pt.c == nil {
+		panic("ProvidedType does not hold a Collection")
+	}
+	return pt.c
+}
+
 // bindShouldUsePointer loads the wire package the user is importing from their
 // injector. The call is a wire marker function call.
 func bindShouldUsePointer(info *types.Info, call *ast.CallExpr) bool {
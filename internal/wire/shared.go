@@ -0,0 +1,276 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// sharedValue is a value declared with wire.Shared. It is built by a
+// generated function the first time an injector in the file needs it.
+type sharedValue struct {
+	// out is the shared type.
+	out types.Type
+	// name is the function that returns the value. The function that
+	// builds it and the variables that hold it are named after it.
+	name string
+	// hasErr is true if building the value can fail.
+	hasErr bool
+	// provider describes the step that produces out, which must be the
+	// same for all injectors.
+	provider string
+	// calls builds the value. It is nil once the functions are written.
+	calls []call
+}
+
+// sharedTypes returns the types declared with wire.Shared in set and the
+// sets it includes.
+func sharedTypes(set *ProviderSet) []types.Type {
+	var shared []types.Type
+	seen := make(map[*ProviderSet]bool)
+	var visit func(*ProviderSet)
+	visit = func(s *ProviderSet) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		for _, sh := range s.Shared {
+			if !containsType(shared, sh.Type) {
+				shared = append(shared, sh.Type)
+			}
+		}
+		for _, imp := range s.Imports {
+			visit(imp)
+		}
+		for _, fb := range s.Fallbacks {
+			visit(fb)
+		}
+	}
+	visit(set)
+	return shared
+}
+
+func containsType(ts []types.Type, t types.Type) bool {
+	for _, u := range ts {
+		if types.Identical(t, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// shareCalls returns calls, the steps of an injector with the given
+// parameters, with the steps that produce a shared type replaced by calls
+// to the function returning the shared value. The steps that are then only
+// needed to build shared values are dropped.
+func (g *gen) shareCalls(calls []call, given *types.Tuple, shared []types.Type) ([]call, []error) {
+	calls = append([]call(nil), calls...)
+	ec := new(errorCollector)
+	replaced := false
+	// Calls are in dependency order, so a shared value needed by another
+	// one is replaced first and the other one's builder calls it.
+	for i := range calls {
+		if !containsType(shared, calls[i].out) {
+			continue
+		}
+		sv, errs := g.sharedValue(calls, given, i)
+		if len(errs) > 0 {
+			ec.add(errs...)
+			continue
+		}
+		calls[i] = call{
+			kind:   funcProviderCall,
+			pkg:    g.pkg.Types,
+			name:   sv.name,
+			out:    sv.out,
+			hasErr: sv.hasErr,
+			shared: true,
+		}
+		replaced = true
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	if !replaced {
+		return calls, nil
+	}
+	numGiven := given.Len()
+	needed := make([]bool, len(calls))
+	needed[len(calls)-1] = true
+	for i := range calls {
+		if calls[i].kind == invokeCall {
+			// Invoked functions are called for their side effects.
+			needed[i] = true
+		}
+	}
+	for i := len(calls) - 1; i >= 0; i-- {
+		if !needed[i] {
+			continue
+		}
+		for _, a := range calls[i].args {
+			if a >= numGiven {
+				needed[a-numGiven] = true
+			}
+		}
+	}
+	return subsetCalls(calls, numGiven, needed), nil
+}
+
+// subsetCalls returns the calls for which keep is true, with their
+// arguments renumbered to match.
+func subsetCalls(calls []call, numGiven int, keep []bool) []call {
+	index := make([]int, len(calls))
+	var sub []call
+	for i, c := range calls {
+		if !keep[i] {
+			continue
+		}
+		index[i] = numGiven + len(sub)
+		args := make([]int, len(c.args))
+		for j, a := range c.args {
+			if a < numGiven {
+				args[j] = a
+			} else {
+				args[j] = index[a-numGiven]
+			}
+		}
+		c.args = args
+		sub = append(sub, c)
+	}
+	return sub
+}
+
+// sharedValue returns the shared value produced by calls[i], adding it to
+// the values to write if no other injector in the file needed it yet.
+func (g *gen) sharedValue(calls []call, given *types.Tuple, i int) (*sharedValue, []error) {
+	out := calls[i].out
+	ts := types.TypeString(out, nil)
+	numGiven := given.Len()
+	ec := new(errorCollector)
+	needed := make([]bool, len(calls))
+	needed[i] = true
+	hasErr := false
+	for k := i; k >= 0; k-- {
+		if !needed[k] {
+			continue
+		}
+		c := &calls[k]
+		if k == i && c.outs != nil {
+			ec.add(fmt.Errorf("shared %s cannot be built by a provider that returns more than one value", ts))
+		}
+		if c.hasCleanup {
+			ec.add(fmt.Errorf("shared %s cannot be built by a provider that returns a cleanup function", ts))
+		}
+		hasErr = hasErr || c.hasErr
+		for _, a := range c.args {
+			if a < numGiven {
+				ec.add(fmt.Errorf("shared %s cannot depend on injector argument %s", ts, types.TypeString(given.At(a).Type(), nil)))
+				continue
+			}
+			needed[a-numGiven] = true
+		}
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	provider := fmt.Sprintf("%v %s", calls[i].kind, calls[i].name)
+	if calls[i].pkg != nil {
+		provider = fmt.Sprintf("%v %s.%s", calls[i].kind, calls[i].pkg.Path(), calls[i].name)
+	}
+	if prev, ok := g.shared.At(out).(*sharedValue); ok {
+		if prev.provider != provider {
+			return nil, []error{fmt.Errorf("shared %s must be provided the same way by all injectors in the file", ts)}
+		}
+		return prev, nil
+	}
+	name := typeVariableName(out, "", func(name string) string { return "_wireShared" + export(name) }, func(name string) bool {
+		for _, n := range sharedNames(name) {
+			if g.nameInFileScope(n) {
+				return true
+			}
+		}
+		return false
+	})
+	sv := &sharedValue{
+		out:      out,
+		name:     name,
+		hasErr:   hasErr,
+		provider: provider,
+		calls:    subsetCalls(calls[:i+1], numGiven, needed[:i+1]),
+	}
+	// The builder has no parameters, so the remaining arguments refer to
+	// its own calls and must be renumbered from zero.
+	for k := range sv.calls {
+		for j := range sv.calls[k].args {
+			sv.calls[k].args[j] -= numGiven
+		}
+	}
+	g.shared.Set(out, sv)
+	g.sharedOrder = append(g.sharedOrder, sv)
+	return sv, nil
+}
+
+// sharedNames returns the names of the function returning a shared value
+// and of the function and variables it uses.
+func sharedNames(name string) []string {
+	return []string{name, name + "Build", name + "Once", name + "Value", name + "Err"}
+}
+
+// writeShared writes the functions and variables for the shared values
+// that have not been written yet.
+func (g *gen) writeShared() {
+	for _, sv := range g.sharedOrder {
+		if sv.calls == nil {
+			continue
+		}
+		names := sharedNames(sv.name)
+		build, once, value, errName := names[1], names[2], names[3], names[4]
+		results := []*types.Var{types.NewVar(0, g.pkg.Types, "", sv.out)}
+		if sv.hasErr {
+			results = append(results, types.NewVar(0, g.pkg.Types, "", errorType))
+		}
+		sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(), types.NewTuple(results...), false)
+		injectPass(build, sig, sv.calls, nil, nil, &injectorGen{
+			g:       g,
+			errVar:  disambiguate("err", g.nameInFileScope),
+			discard: true,
+		})
+		injectPass(build, sig, sv.calls, nil, nil, &injectorGen{
+			g:       g,
+			errVar:  disambiguate("err", g.nameInFileScope),
+			discard: false,
+		})
+		outType := types.TypeString(sv.out, g.qualifyPkg)
+		g.p("var (\n")
+		g.p("\t%s %s\n", once, g.qualifiedID("sync", "sync", "Once"))
+		g.p("\t%s %s\n", value, outType)
+		if sv.hasErr {
+			g.p("\t%s error\n", errName)
+		}
+		g.p(")\n\n")
+		g.p("// %s returns the %s shared by the injectors in this file.\n", sv.name, types.TypeString(sv.out, g.qualifyPkg))
+		if sv.hasErr {
+			g.p("func %s() (%s, error) {\n", sv.name, outType)
+			g.p("\t%s.Do(func() {\n\t\t%s, %s = %s()\n\t})\n", once, value, errName, build)
+			g.p("\treturn %s, %s\n}\n\n", value, errName)
+		} else {
+			g.p("func %s() %s {\n", sv.name, outType)
+			g.p("\t%s.Do(func() {\n\t\t%s = %s()\n\t})\n", once, value, build)
+			g.p("\treturn %s\n}\n\n", value)
+		}
+		sv.calls = nil
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// A CallInfo describes a step of the plan returned by Solve.
+type CallInfo struct {
+	// Provider describes what the step calls or reads, in the form used
+	// by Graph: the qualified name of a provider function, "struct"
+	// followed by the qualified name of a struct type, "value", "field"
+	// followed by a field name, and so on.
+	Provider string
+	// Out is the type the step produces, as printed by types.TypeString.
+	Out string
+	// Args holds the indices of the earlier steps whose values the step
+	// takes, in argument order.
+	Args []int
+}
+
+// Solve loads the package with the given import path like Load and returns
+// the steps that an injector without arguments would take to build out from
+// the given provider sets, in order. This allows checking that provider
+// sets can build a type without generating an injector.
+//
+// Provider sets and types are written as types.TypeString prints them,
+// such as "example.com/foo.Set" and "*example.com/foo.Bar". A provider set
+// name without an import path refers to a set in pkg. Types must be
+// predeclared or declared in pkg or one of its dependencies.
+func Solve(ctx context.Context, wd string, env []string, injectTag, tags string, pkg string, sets []string, out string) ([]CallInfo, []error) {
+	pkgs, errs := load(ctx, wd, env, injectTag, tags, []string{pkg})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(pkgs) != 1 {
+		return nil, []error{fmt.Errorf("%s matches %d packages; want 1", pkg, len(pkgs))}
+	}
+	oc := newObjectCache(pkgs)
+	pkg = pkgs[0].PkgPath
+	ec := new(errorCollector)
+	set := &ProviderSet{PkgPath: pkg}
+	for _, ref := range sets {
+		path, name := pkg, ref
+		if i := strings.LastIndex(ref, "."); i != -1 {
+			path, name = ref[:i], ref[i+1:]
+		}
+		obj := oc.lookup(path, name)
+		if obj == nil || !isProviderSetType(obj.Type()) {
+			ec.add(fmt.Errorf("%s is not a provider set", ref))
+			continue
+		}
+		item, errs := oc.get(obj)
+		if len(errs) > 0 {
+			ec.add(notePositionAll(oc.fset.Position(obj.Pos()), errs)...)
+			continue
+		}
+		set.Imports = append(set.Imports, item.(*ProviderSet))
+	}
+	outType, err := oc.resolveType(out)
+	if err != nil {
+		ec.add(err)
+	}
+	if len(ec.errors) > 0 {
+		return nil, ec.errors
+	}
+	set.providerMap, set.srcMap, errs = buildProviderMap(oc.fset, oc.hasher, set, oc.strictBindings)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if errs := verifyAcyclic(oc.fset, set.providerMap, oc.hasher); len(errs) > 0 {
+		return nil, errs
+	}
+	calls, errs := solve(oc.fset, outType, types.NewTuple(), set, nil)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	infos := make([]CallInfo, len(calls))
+	for i := range calls {
+		c := &calls[i]
+		infos[i] = CallInfo{
+			Provider: callLabel(c),
+			Out:      types.TypeString(c.out, nil),
+			Args:     append([]int(nil), c.args...),
+		}
+	}
+	return infos, nil
+}
+
+// lookup returns the package-level object with the given name in the
+// package with the given import path, or nil if there is none.
+func (oc *objectCache) lookup(path, name string) types.Object {
+	pkg := oc.packages[path]
+	if pkg == nil {
+		return nil
+	}
+	return pkg.Types.Scope().Lookup(name)
+}
+
+// resolveType returns the type that types.TypeString prints as s. Only
+// pointers, slices, predeclared types and named types are supported.
+func (oc *objectCache) resolveType(s string) (types.Type, error) {
+	switch {
+	case strings.HasPrefix(s, "*"):
+		elem, err := oc.resolveType(s[1:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewPointer(elem), nil
+	case strings.HasPrefix(s, "[]"):
+		elem, err := oc.resolveType(s[2:])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewSlice(elem), nil
+	}
+	var obj types.Object
+	if i := strings.LastIndex(s, "."); i != -1 {
+		obj = oc.lookup(s[:i], s[i+1:])
+	} else {
+		obj = types.Universe.Lookup(s)
+	}
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("cannot find type %s", s)
+	}
+	return tn.Type(), nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// suggest returns the hints enabled by g's options for a type that has no
+// provider, one per line, or the empty string if there are none.
+func (g *gen) suggest(t types.Type) string {
+	var hints []string
+	if g.opts.AmbientContext && isContextType(t) {
+		hints = append(hints, "hint: declare a ctx context.Context parameter on the injector to pass a context to the providers that need one")
+	}
+	if g.opts.SuggestProviders {
+		if s := g.suggestProviders(t); s != "" {
+			hints = append(hints, s)
+		}
+	}
+	return strings.Join(hints, "\n")
+}
+
+// isContextType reports whether t is context.Context.
+func isContextType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
+// suggestProviders returns a hint naming the functions in the loaded
+// packages that return t, or the empty string if there are none.
+func (g *gen) suggestProviders(t types.Type) string {
+	if g.providerCandidates == nil {
+		g.providerCandidates = g.indexProviderCandidates()
+	}
+	names, _ := g.providerCandidates.At(t).([]string)
+	if len(names) == 0 {
+		return ""
+	}
+	ts := types.TypeString(t, nil)
+	if len(names) == 1 {
+		return fmt.Sprintf("hint: %s is returned by %s, which is not in the provider set; add it, or a provider set that contains it, to wire.Build", ts, names[0])
+	}
+	return fmt.Sprintf("hint: %s is returned by %s, which are not in the provider set; add one of them, or a provider set that contains it, to wire.Build", ts, strings.Join(names[:len(names)-1], ", ")+" and "+names[len(names)-1])
+}
+
+// indexProviderCandidates maps each type to the sorted names of the
+// package-level functions that could provide it. Functions are taken from
+// the package being generated and the non-standard library packages it
+// depends on; only exported functions are considered outside of the
+// package itself. Injectors and generic functions are skipped.
+func (g *gen) indexProviderCandidates() *typeutil.Map {
+	injectors := make(map[types.Object]bool)
+	for _, f := range g.pkg.Syntax {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if buildCall, _ := findInjectorBuild(g.pkg.TypesInfo, fn); buildCall != nil {
+				injectors[g.pkg.TypesInfo.ObjectOf(fn.Name)] = true
+			}
+		}
+	}
+	index := new(typeutil.Map)
+	packages.Visit([]*packages.Package{g.pkg}, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil || (pkg != g.pkg && isStandardLibrary(pkg)) {
+			return
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			fn, ok := scope.Lookup(name).(*types.Func)
+			if !ok || injectors[fn] || (pkg != g.pkg && !fn.Exported()) {
+				continue
+			}
+			sig := fn.Type().(*types.Signature)
+			if sig.TypeParams().Len() > 0 {
+				continue
+			}
+			out, _, err := providerOutput(sig)
+			if err != nil {
+				continue
+			}
+			names, _ := index.At(out.out).([]string)
+			index.Set(out.out, append(names, pkg.PkgPath+"."+name))
+		}
+	})
+	index.Iterate(func(_ types.Type, v interface{}) {
+		sort.Strings(v.([]string))
+	})
+	return index
+}
+
+// isStandardLibrary reports whether pkg belongs to the standard library,
+// which is the only code in a build that does not belong to a module.
+// Outside of module mode, every package is treated as such.
+func isStandardLibrary(pkg *packages.Package) bool {
+	return pkg.Module == nil
+}
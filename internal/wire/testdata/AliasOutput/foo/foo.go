@@ -0,0 +1,47 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func main() {
+	rec := httptest.NewRecorder()
+	injectHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	fmt.Println(rec.Body.String())
+	fmt.Println(injectGreeting("hi"))
+	fmt.Println(injectName())
+}
+
+// Handler is an alias, so it is the same type as http.Handler.
+type Handler = http.Handler
+
+// Name is an alias for a type defined in this package.
+type Name = name
+
+type name string
+
+func provideHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello")
+	})
+}
+
+func provideName() name {
+	return "gopher"
+}
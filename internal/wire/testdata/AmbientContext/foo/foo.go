@@ -0,0 +1,47 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+)
+
+type key struct{}
+
+type DB struct {
+	name string
+}
+
+type Server struct {
+	db   *DB
+	port Port
+}
+
+type Port int
+
+type Version string
+
+func NewDB(ctx context.Context) (*DB, error) {
+	name, _ := ctx.Value(key{}).(string)
+	return &DB{name: name}, nil
+}
+
+func NewServer(ctx context.Context, db *DB, port Port) *Server {
+	return &Server{db: db, port: port}
+}
+
+func provideVersion() Version {
+	return "v1"
+}
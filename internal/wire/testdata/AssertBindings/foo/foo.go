@@ -0,0 +1,42 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"example.com/bar"
+)
+
+func main() {
+	fmt.Println(injectFooer().Foo())
+	fmt.Println(injectGreeter().Greet())
+}
+
+type Greeter interface {
+	Greet() string
+}
+
+type Message struct {
+	Fooer bar.Fooer
+}
+
+func (m Message) Greet() string {
+	return "Greetings from " + m.Fooer.Foo()
+}
+
+func provideMessage(f bar.Fooer) Message {
+	return Message{Fooer: f}
+}
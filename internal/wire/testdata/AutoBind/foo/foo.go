@@ -0,0 +1,59 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	fmt.Println(injectService().Describe())
+}
+
+type Fooer interface {
+	Foo() string
+}
+
+type Bar string
+
+func (b *Bar) Foo() string {
+	return string(*b)
+}
+
+// Baz does not implement Fooer, so it is not a candidate.
+type Baz int
+
+func provideBar() *Bar {
+	b := Bar("bar")
+	return &b
+}
+
+func provideBaz() Baz {
+	return 1
+}
+
+type Service struct {
+	fooer Fooer
+	baz   Baz
+}
+
+// NewService depends on Fooer, which is satisfied by *Bar without a binding.
+func NewService(f Fooer, b Baz) *Service {
+	return &Service{fooer: f, baz: b}
+}
+
+func (s *Service) Describe() string {
+	return fmt.Sprint(s.fooer.Foo(), s.baz)
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+func main() {}
+
+type Fooer interface {
+	Foo() string
+}
+
+type Bar string
+
+func (b Bar) Foo() string {
+	return string(b)
+}
+
+type Qux int
+
+func (q Qux) Foo() string {
+	return "qux"
+}
+
+func provideBar() Bar {
+	return "bar"
+}
+
+func provideQux() Qux {
+	return 1
+}
+
+type Service struct {
+	fooer Fooer
+}
+
+func NewService(f Fooer) *Service {
+	return &Service{fooer: f}
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	fmt.Println(injectServer().Describe())
+	fmt.Println(injectConfig().Addr)
+}
+
+type Config struct {
+	Addr string
+}
+
+// loadConfig returns a pointer, but NewServer takes a Config by value.
+func loadConfig() *Config {
+	return &Config{Addr: ":8080"}
+}
+
+type Logger struct {
+	Prefix string
+}
+
+// newLogger returns a value, but NewServer takes a *Logger.
+func newLogger() Logger {
+	return Logger{Prefix: "server"}
+}
+
+type Server struct {
+	cfg Config
+	log *Logger
+}
+
+func NewServer(cfg Config, log *Logger) *Server {
+	return &Server{cfg: cfg, log: log}
+}
+
+func (s *Server) Describe() string {
+	return s.log.Prefix + s.cfg.Addr
+}
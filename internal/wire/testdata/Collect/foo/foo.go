@@ -0,0 +1,79 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	mux := injectMux("Hello, World!")
+	for _, path := range []string{"/hello", "/healthz"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		fmt.Println(w.Body.String())
+	}
+}
+
+type Greeting string
+
+// Route is an HTTP handler that knows where it should be mounted.
+type Route interface {
+	http.Handler
+	Pattern() string
+}
+
+type HelloRoute struct {
+	greeting Greeting
+}
+
+func NewHelloRoute(greeting Greeting) *HelloRoute {
+	return &HelloRoute{greeting: greeting}
+}
+
+func (*HelloRoute) Pattern() string { return "/hello" }
+
+func (h *HelloRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, string(h.greeting))
+}
+
+type HealthRoute struct{}
+
+func NewHealthRoute() HealthRoute {
+	return HealthRoute{}
+}
+
+func (HealthRoute) Pattern() string { return "/healthz" }
+
+func (HealthRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, "ok")
+}
+
+func NewMux(routes ...Route) *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, r := range routes {
+		mux.Handle(r.Pattern(), r)
+	}
+	return mux
+}
+
+var Set = wire.NewSet(
+	wire.Collect(new([]Route), NewHelloRoute, NewHealthRoute),
+	NewMux)
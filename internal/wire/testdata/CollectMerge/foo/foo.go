@@ -0,0 +1,65 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	fmt.Println(injectRegistry())
+}
+
+// Plugin is implemented by the plugins of several sets.
+type Plugin interface {
+	Name() string
+}
+
+type Hello struct{}
+
+func (Hello) Name() string { return "hello" }
+
+func NewHello() Hello { return Hello{} }
+
+type Health struct{}
+
+func (Health) Name() string { return "health" }
+
+func NewHealth() Health { return Health{} }
+
+type Metrics struct{}
+
+func (Metrics) Name() string { return "metrics" }
+
+func NewMetrics() Metrics { return Metrics{} }
+
+type Registry string
+
+func NewRegistry(plugins ...Plugin) Registry {
+	var names []string
+	for _, p := range plugins {
+		names = append(names, p.Name())
+	}
+	return Registry(strings.Join(names, ","))
+}
+
+// Each set contributes its own plugins to []Plugin.
+var (
+	HelloSet  = wire.NewSet(wire.Collect(new([]Plugin), NewHello))
+	HealthSet = wire.NewSet(wire.Collect(new([]Plugin), NewHealth, NewHello))
+)
@@ -0,0 +1,65 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	fmt.Println(injectApp())
+	fmt.Println("metrics created:", created)
+}
+
+var created int
+
+type Plugin interface {
+	Name() string
+}
+
+type Metrics struct{}
+
+func (*Metrics) Name() string { return "metrics" }
+
+// NewMetrics is both collected and provided, so that the handler can
+// export the metrics it collects.
+func NewMetrics() *Metrics {
+	created++
+	return &Metrics{}
+}
+
+type Tracing struct{}
+
+func (Tracing) Name() string { return "tracing" }
+
+func NewTracing() Tracing { return Tracing{} }
+
+type App string
+
+func NewApp(m *Metrics, plugins ...Plugin) App {
+	var names []string
+	for _, p := range plugins {
+		names = append(names, p.Name())
+	}
+	return App(m.Name() + ": " + strings.Join(names, ","))
+}
+
+var Set = wire.NewSet(
+	NewMetrics,
+	wire.Collect(new([]Plugin), NewMetrics, NewTracing),
+	NewApp)
@@ -0,0 +1,43 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func main() {
+	fmt.Println(injectServer(8080).Addr)
+	fmt.Println(injectHostName())
+}
+
+// Port is a TCP port number.
+type Port int
+
+// Host is a network host name.
+type Host string
+
+type Server struct {
+	Addr string
+}
+
+func provideHost() Host {
+	return "localhost"
+}
+
+func NewServer(host Host, port Port) *Server {
+	return &Server{Addr: string(host) + ":" + strconv.Itoa(int(port))}
+}
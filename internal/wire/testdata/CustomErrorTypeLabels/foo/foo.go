@@ -0,0 +1,75 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	for _, f := range []Fail{"", "config", "db", "server"} {
+		s, err := injectServer(f)
+		fmt.Println(s != nil, err)
+	}
+}
+
+type Fail string
+
+// ConfigError is an error interface of the application.
+type ConfigError interface {
+	error
+	Key() string
+}
+
+type keyError string
+
+func (e keyError) Error() string { return "bad key " + string(e) }
+func (e keyError) Key() string   { return string(e) }
+
+// DBError is a concrete error type. NewDB returns a nil *DBError when it
+// succeeds, which must not be taken for an error.
+type DBError struct {
+	Op string
+}
+
+func (e *DBError) Error() string { return "db: " + e.Op + " failed" }
+
+type Config struct{}
+
+type DB struct{}
+
+type Server struct{}
+
+func NewConfig(f Fail) (*Config, ConfigError) {
+	if f == "config" {
+		return nil, keyError("port")
+	}
+	return new(Config), nil
+}
+
+func NewDB(f Fail, c *Config) (*DB, *DBError) {
+	if f == "db" {
+		return nil, &DBError{Op: "open"}
+	}
+	return new(DB), nil
+}
+
+func NewServer(f Fail, db *DB) (*Server, error) {
+	if f == "server" {
+		return nil, errors.New("server failed")
+	}
+	return new(Server), nil
+}
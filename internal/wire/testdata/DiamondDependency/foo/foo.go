@@ -0,0 +1,57 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	top, err := injectTop()
+	fmt.Println(top, err)
+	fmt.Println("conns opened:", opened)
+}
+
+var opened int
+
+// Conn is needed by both A and B, directly and through the Pinger binding.
+type Conn struct{}
+
+func (*Conn) Ping() error { return nil }
+
+type Pinger interface {
+	Ping() error
+}
+
+func provideConn() (*Conn, error) {
+	opened++
+	return &Conn{}, nil
+}
+
+type A string
+type B string
+type Top string
+
+func provideA(c *Conn) A {
+	return "a"
+}
+
+func provideB(p Pinger) (B, error) {
+	return "b", p.Ping()
+}
+
+func provideTop(a A, b B) Top {
+	return Top(string(a) + string(b))
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	for _, fail := range []string{"", "config", "db", "server"} {
+		s, cleanup, err := injectServer(Fail(fail))
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		fmt.Println(s.Addr)
+		cleanup()
+	}
+	for _, fail := range []string{"", "config"} {
+		cfg, err := injectConfig(Fail(fail))
+		fmt.Println(cfg, err)
+	}
+}
+
+type Fail string
+
+type Config struct {
+	Addr string
+}
+
+type DB struct{}
+
+type Handler struct {
+	DB *DB
+}
+
+type Server struct {
+	Addr    string
+	Handler *Handler
+}
+
+func provideConfig(f Fail) (Config, error) {
+	if f == "config" {
+		return Config{}, errors.New("bad config")
+	}
+	return Config{Addr: ":8080"}, nil
+}
+
+func provideDB(f Fail) (*DB, func(), error) {
+	if f == "db" {
+		return nil, nil, errors.New("no database")
+	}
+	return &DB{}, func() { fmt.Println("close db") }, nil
+}
+
+func provideServer(f Fail, cfg Config, h *Handler) (*Server, func(), error) {
+	if f == "server" {
+		return nil, nil, errors.New("cannot listen")
+	}
+	return &Server{Addr: cfg.Addr, Handler: h}, func() { fmt.Println("stop server") }, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	srv, cleanup, err := injectServer()
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	defer cleanup()
+	fmt.Println(srv.db.name)
+}
+
+type DB struct {
+	name string
+}
+
+type Server struct {
+	db *DB
+}
+
+func NewDB() (*DB, func(), error) {
+	return &DB{name: "primary"}, func() {}, nil
+}
+
+func NewServer(db *DB) (*Server, error) {
+	if db == nil {
+		return nil, errors.New("no database")
+	}
+	return &Server{db: db}, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	app := injectApp()
+	fmt.Println(app.store, app.logger, app.clock)
+}
+
+type Store string
+type Logger string
+type Clock string
+
+// ProductionSet provides the real store and logger.
+var ProductionSet = wire.NewSet(provideStore, provideLogger)
+
+// DefaultsSet provides defaults for everything. Its store and logger are
+// only used for types ProductionSet doesn't provide.
+var DefaultsSet = wire.NewSet(provideDefaultStore, provideDefaultLogger, provideDefaultClock)
+
+func provideStore() Store {
+	return "postgres"
+}
+
+func provideLogger() Logger {
+	return "stackdriver"
+}
+
+func provideDefaultStore() Store {
+	return "memory"
+}
+
+func provideDefaultLogger() Logger {
+	return "stderr"
+}
+
+func provideDefaultClock() Clock {
+	return "system"
+}
+
+type App struct {
+	store  Store
+	logger Logger
+	clock  Clock
+}
+
+func provideApp(store Store, logger Logger, clock Clock) *App {
+	return &App{store: store, logger: logger, clock: clock}
+}
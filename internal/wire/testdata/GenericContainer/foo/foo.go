@@ -0,0 +1,61 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"container/list"
+	"fmt"
+)
+
+func main() {
+	w := injectWorker()
+	fmt.Println(w.queue.Len(), w.history.Len(), w.current.Name)
+}
+
+type Job struct {
+	Name string
+}
+
+type Queue[T any] struct {
+	items []T
+}
+
+func (q *Queue[T]) Len() int {
+	return len(q.items)
+}
+
+// provideQueue returns an empty queue. It does not take a Job, so Wire must
+// not try to provide one to it.
+func provideQueue() *Queue[Job] {
+	return &Queue[Job]{}
+}
+
+func provideHistory() *list.List {
+	return list.New()
+}
+
+func provideJob() Job {
+	return Job{Name: "build"}
+}
+
+type Worker struct {
+	queue   *Queue[Job]
+	history *list.List
+	current Job
+}
+
+func provideWorker(queue *Queue[Job], history *list.List, current Job) *Worker {
+	return &Worker{queue: queue, history: history, current: current}
+}
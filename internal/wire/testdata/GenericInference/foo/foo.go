@@ -0,0 +1,57 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	s := injectService()
+	fmt.Println(s.users.cache.name, s.hits.name)
+}
+
+type Cache[T any] struct {
+	name  string
+	items map[string]T
+}
+
+// NewCache is a generic provider whose type argument is inferred from the
+// type that is needed.
+func NewCache[T any]() *Cache[T] {
+	var zero T
+	return &Cache[T]{name: fmt.Sprintf("%T", zero), items: make(map[string]T)}
+}
+
+type Repo[T any] struct {
+	cache *Cache[T]
+}
+
+func NewRepo[T any](cache *Cache[T]) *Repo[T] {
+	return &Repo[T]{cache: cache}
+}
+
+type Service struct {
+	users *Repo[string]
+	hits  *Cache[int]
+}
+
+func NewService(users *Repo[string], hits *Cache[int]) *Service {
+	return &Service{users: users, hits: hits}
+}
+
+var Set = wire.NewSet(wire.Generic(NewCache[any]), wire.Generic(NewRepo[any]), NewService)
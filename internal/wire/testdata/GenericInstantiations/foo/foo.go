@@ -0,0 +1,53 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	s := injectStores()
+	fmt.Println(s.names.get(), s.counts.get())
+}
+
+type Cache[T any] struct {
+	v T
+}
+
+func (c *Cache[T]) get() T {
+	return c.v
+}
+
+func NewCache[T any](v T) *Cache[T] {
+	return &Cache[T]{v: v}
+}
+
+type Stores struct {
+	names  *Cache[string]
+	counts *Cache[int]
+}
+
+func provideStores(names *Cache[string], counts *Cache[int]) Stores {
+	return Stores{names: names, counts: counts}
+}
+
+func provideName() string {
+	return "alice"
+}
+
+func provideCount() int {
+	return 3
+}
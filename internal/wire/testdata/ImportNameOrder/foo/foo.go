@@ -0,0 +1,34 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	aconfig "example.com/a/config"
+	zconfig "example.com/z/config"
+)
+
+func main() {
+	fmt.Println(injectApp())
+}
+
+type App string
+
+// newApp needs example.com/z/config first, but example.com/a/config is
+// imported under the name config since its path sorts first.
+func newApp(z *zconfig.Config, a *aconfig.Config) App {
+	return App(z.Name + a.Name)
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bar has an injector that example.com/foo uses as a provider.
+package bar
+
+type DSN string
+
+type DB struct {
+	DSN DSN
+}
+
+func ProvideDSN() DSN {
+	return "postgres://localhost"
+}
+
+func ProvideDB(dsn DSN) (*DB, func(), error) {
+	return &DB{DSN: dsn}, func() {}, nil
+}
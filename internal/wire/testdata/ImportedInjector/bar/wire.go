@@ -0,0 +1,32 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build wireinject
+
+package bar
+
+import (
+	"github.com/google/wire"
+)
+
+// InitDB connects to the default database.
+func InitDB() (*DB, func(), error) {
+	wire.Build(ProvideDSN, ProvideDB)
+	return nil, nil, nil
+}
+
+// DefaultDSN returns the DSN that InitDB connects to.
+func DefaultDSN() DSN {
+	return ProvideDSN()
+}
@@ -0,0 +1,44 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"example.com/bar"
+	"github.com/google/wire"
+)
+
+func main() {
+	app, cleanup, err := injectApp()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer cleanup()
+	fmt.Println(app.DB.DSN)
+}
+
+type App struct {
+	DB *bar.DB
+}
+
+func provideApp(db *bar.DB) *App {
+	return &App{DB: db}
+}
+
+// AppSet uses the injector generated for package bar like any other
+// provider.
+var AppSet = wire.NewSet(bar.InitDB, provideApp)
@@ -0,0 +1,51 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+)
+
+// wrong: provideServer is already declared.
+//
+//wire:name provideServer
+func injectServer(addr string) *Server {
+	wire.Build(provideServer)
+	return nil
+}
+
+// wrong: not an identifier.
+//
+//wire:name New-Server
+func injectServer2(addr string) *Server {
+	wire.Build(provideServer)
+	return nil
+}
+
+//wire:name NewServer
+func injectServer3(addr string) *Server {
+	wire.Build(provideServer)
+	return nil
+}
+
+// wrong: injectServer3 is already renamed to NewServer.
+//
+//wire:name NewServer
+func injectServer4(addr string) *Server {
+	wire.Build(provideServer)
+	return nil
+}
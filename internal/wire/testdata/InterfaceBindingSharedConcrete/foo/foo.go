@@ -0,0 +1,61 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	rw := injectReadWriter()
+	rw.Writer.Write("hello")
+	fmt.Println(provideImplCalls, rw.Reader.Read(), rw.Writer.(*Impl).buf)
+}
+
+type Reader interface {
+	Read() string
+}
+
+type Writer interface {
+	Write(s string)
+}
+
+type Impl struct {
+	buf string
+}
+
+func (i *Impl) Read() string {
+	return i.buf
+}
+
+func (i *Impl) Write(s string) {
+	i.buf = s
+}
+
+type ReadWriter struct {
+	Reader Reader
+	Writer Writer
+}
+
+var provideImplCalls int
+
+func provideImpl() *Impl {
+	provideImplCalls++
+	return new(Impl)
+}
+
+func provideReadWriter(r Reader, w Writer) ReadWriter {
+	return ReadWriter{Reader: r, Writer: w}
+}
@@ -0,0 +1,40 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "fmt"
+
+func main() {
+	s, err := injectStorage("postgres://db")
+	fmt.Println(s.Name(), err)
+}
+
+type Storage interface {
+	Name() string
+}
+
+type DSN string
+
+type PostgresStorage struct {
+	dsn DSN
+}
+
+func (s *PostgresStorage) Name() string {
+	return "postgres at " + string(s.dsn)
+}
+
+func NewPostgresStorage(dsn DSN) (*PostgresStorage, error) {
+	return &PostgresStorage{dsn: dsn}, nil
+}
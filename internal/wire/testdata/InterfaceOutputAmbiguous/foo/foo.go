@@ -0,0 +1,41 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(injectStorage().Name())
+}
+
+type Storage interface {
+	Name() string
+}
+
+type MemoryStorage struct{}
+
+func (*MemoryStorage) Name() string { return "memory" }
+
+type DiskStorage struct{}
+
+func (*DiskStorage) Name() string { return "disk" }
+
+func NewMemoryStorage() *MemoryStorage {
+	return new(MemoryStorage)
+}
+
+func NewDiskStorage() *DiskStorage {
+	return new(DiskStorage)
+}
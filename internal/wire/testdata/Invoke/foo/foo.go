@@ -0,0 +1,69 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	app, err := injectApp()
+	fmt.Println(app.Registry.Names, err)
+	_, err = injectBroken()
+	fmt.Println(err)
+}
+
+type Registry struct {
+	Names []string
+}
+
+type DB struct {
+	Name string
+}
+
+type App struct {
+	Registry *Registry
+	DB       *DB
+}
+
+func NewRegistry() *Registry {
+	return new(Registry)
+}
+
+func NewDB() *DB {
+	return &DB{Name: "db"}
+}
+
+func NewApp(r *Registry, db *DB) *App {
+	return &App{Registry: r, DB: db}
+}
+
+func RegisterDB(r *Registry, db *DB) {
+	r.Names = append(r.Names, db.Name)
+}
+
+func RegisterBuiltins(r *Registry) error {
+	r.Names = append(r.Names, "builtins")
+	return nil
+}
+
+func FailToRegister(r *Registry) error {
+	return errors.New("registration failed")
+}
+
+var Set = wire.NewSet(NewRegistry, NewDB, NewApp)
@@ -0,0 +1,49 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	fmt.Println(injectServer())
+}
+
+type Registry struct{}
+
+type Server struct{}
+
+func NewRegistry() *Registry {
+	return new(Registry)
+}
+
+func NewServer(r *Registry) *Server {
+	return new(Server)
+}
+
+func RegisterServer(s *Server) {}
+
+func ValidateRegistry(r *Registry) error {
+	return nil
+}
+
+func ProvideName(r *Registry) string {
+	return "name"
+}
+
+var Set = wire.NewSet(NewRegistry, NewServer)
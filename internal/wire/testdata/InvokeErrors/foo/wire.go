@@ -0,0 +1,39 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+)
+
+func injectServer() *Server {
+	// RegisterServer needs the output, so it cannot run before it is built.
+	wire.Build(Set, wire.Invoke(RegisterServer))
+	return nil
+}
+
+func injectInfallible() *Server {
+	// ValidateRegistry can fail, but the injector cannot.
+	wire.Build(Set, wire.Invoke(ValidateRegistry))
+	return nil
+}
+
+func injectWithResult() *Server {
+	// ProvideName returns a value, so it is a provider.
+	wire.Build(Set, wire.Invoke(ProvideName))
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+type Store struct {
+	name string
+}
+
+type Session struct {
+	store *Store
+	user  string
+}
+
+type User string
+
+func provideStore() *Store {
+	return &Store{name: "db"}
+}
+
+func (s *Store) Session(u User) *Session {
+	return &Session{store: s, user: string(u)}
+}
+
+func main() {
+	s := injectSession("gopher")
+	fmt.Println(s.store.name, s.user)
+}
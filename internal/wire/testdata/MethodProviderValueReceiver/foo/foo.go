@@ -0,0 +1,41 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+type Config struct {
+	Timeout int
+}
+
+type Client struct {
+	timeout int
+}
+
+func provideConfig() Config {
+	return Config{Timeout: 30}
+}
+
+// HTTPClient has a value receiver, so the injector calls it on the Config
+// it receives from provideConfig.
+func (c Config) HTTPClient() *Client {
+	return &Client{timeout: c.Timeout}
+}
+
+func main() {
+	fmt.Println(injectClient().timeout)
+}
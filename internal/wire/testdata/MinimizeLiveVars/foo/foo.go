@@ -0,0 +1,47 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	fmt.Println(injectReport())
+}
+
+type (
+	Title  string
+	Author string
+	Intro  string
+	Body   string
+	Outro  string
+	Text   string
+	Report string
+)
+
+func provideTitle() Title   { return "Report" }
+func provideAuthor() Author { return "Gopher" }
+func provideIntro() Intro   { return "Hello" }
+func provideBody() Body     { return "world" }
+func provideOutro() Outro   { return "!" }
+
+func provideText(i Intro, b Body, o Outro) Text {
+	return Text(string(i) + ", " + string(b) + string(o))
+}
+
+func provideReport(t Title, a Author, text Text) Report {
+	return Report(string(t) + " by " + string(a) + ": " + string(text))
+}
@@ -38,7 +38,7 @@ func injectFromNestedSet() Foo {
 }
 
 func injectFromSetWithDuplicateBindings() Foo {
-	// fail: DuplicateBindingsSet has two providers for Foo.
+	// SetWithDuplicateBindings imports Set twice, which is not a conflict.
 	panic(wire.Build(SetWithDuplicateBindings))
 }
 
@@ -48,6 +48,7 @@ func injectDuplicateValues() Foo {
 }
 
 func injectDuplicateInterface() Bar {
-	// fail: provideBar and wire.Bind both provide Bar.
+	// fail: provideBar and wire.Bind both provide Bar, since the test runs
+	// with StrictBindings.
 	panic(wire.Build(provideBar, wire.Bind(new(Bar), new(*strings.Reader))))
 }
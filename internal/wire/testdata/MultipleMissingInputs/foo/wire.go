@@ -21,7 +21,7 @@ import (
 )
 
 func injectMissingOutputType() Foo {
-	// Error: no provider for Foo.
+	// Error: no provider for Foo, since wire.Build is empty.
 	wire.Build()
 	return Foo(0)
 }
@@ -0,0 +1,40 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+)
+
+type Greeting string
+
+type Config struct {
+	retries int
+}
+
+func provideGreeting(s string) (Greeting, error) {
+	if s == "" {
+		return "", errors.New("empty greeting")
+	}
+	return Greeting(s + ", world"), nil
+}
+
+func provideConfig(retries int) (*Config, func(), error) {
+	return &Config{retries: retries}, func() {}, nil
+}
+
+func provideCount() int {
+	return 42
+}
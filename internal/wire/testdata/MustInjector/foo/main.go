@@ -0,0 +1,36 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// The panicking injectors only exist in the generated code, so main is
+// excluded from the build that Wire analyzes.
+
+//+build !wireinject
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	fmt.Println(mustInjectGreeting("hello"))
+	cfg, cleanup := MustInjectConfig(3)
+	defer cleanup()
+	fmt.Println(cfg.retries)
+	fmt.Println(injectCount())
+	defer func() {
+		fmt.Println("recovered:", recover())
+	}()
+	mustInjectGreeting("")
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+)
+
+//wire:must
+func injectGreeting(s string) (Greeting, error) {
+	wire.Build(provideGreeting)
+	return "", nil
+}
+
+// InjectConfig builds a Config.
+//
+//wire:must
+func InjectConfig(retries int) (*Config, func(), error) {
+	wire.Build(provideConfig)
+	return nil, nil, nil
+}
+
+// injectCount can't fail, so no panicking variant is generated.
+//
+//wire:must
+func injectCount() int {
+	wire.Build(provideCount)
+	return 0
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	fmt.Println(injectCheckout().Pay(10))
+}
+
+type PaymentGateway interface {
+	Charge(amount int) string
+}
+
+type RealGateway struct{}
+
+func (*RealGateway) Charge(amount int) string {
+	return fmt.Sprintf("charged %d", amount)
+}
+
+func NewRealGateway() *RealGateway {
+	return new(RealGateway)
+}
+
+type StubGateway struct{}
+
+func (StubGateway) Charge(amount int) string {
+	return fmt.Sprintf("pretended to charge %d", amount)
+}
+
+func NewStubGateway() PaymentGateway {
+	return StubGateway{}
+}
+
+type Checkout struct {
+	Gateway PaymentGateway
+}
+
+func (c *Checkout) Pay(amount int) string {
+	return c.Gateway.Charge(amount)
+}
+
+func NewCheckout(g PaymentGateway) *Checkout {
+	return &Checkout{Gateway: g}
+}
+
+var ProductionSet = wire.NewSet(
+	NewRealGateway,
+	wire.Bind(new(PaymentGateway), new(*RealGateway)),
+	NewCheckout)
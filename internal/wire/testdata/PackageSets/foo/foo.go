@@ -0,0 +1,54 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	fmt.Println(injectApp())
+}
+
+type Greeting string
+
+type App struct {
+	Greeting Greeting
+	Count    int
+}
+
+func (a App) String() string {
+	return fmt.Sprintf("%s %d", a.Greeting, a.Count)
+}
+
+func provideGreeting() Greeting {
+	return "hello"
+}
+
+func provideCount() int {
+	return 42
+}
+
+func provideApp(g Greeting, n int) App {
+	return App{Greeting: g, Count: n}
+}
+
+var (
+	GreetingSet = wire.NewSet(provideGreeting)
+	CountSet    = wire.NewSet(provideCount)
+	AppSet      = wire.NewSet(provideApp)
+)
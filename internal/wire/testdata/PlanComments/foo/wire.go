@@ -0,0 +1,40 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build wireinject
+
+package main
+
+import (
+	"example.com/bar"
+	"github.com/google/wire"
+)
+
+// injectServer builds the server.
+func injectServer() (*Server, error) {
+	wire.Build(bar.NewConfig, wire.Value(Name("api")), wire.Struct(new(Handler), "*"), NewServer)
+	return nil, nil
+}
+
+// injectConn uses both results of NewPipe.
+func injectConn() *Conn {
+	wire.Build(NewPipe, NewConn)
+	return nil
+}
+
+// injectWriter discards the first result of NewPipe.
+func injectWriter() *Writer {
+	wire.Build(NewPipe)
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	fmt.Println(injectBar().Foo.Name)
+}
+
+type Foo struct {
+	Name string
+}
+
+type Bar struct {
+	Foo *Foo
+}
+
+func NewBar(foo *Foo) *Bar {
+	return &Bar{Foo: foo}
+}
+
+func NewProdFoo() *Foo {
+	return &Foo{Name: "prod"}
+}
+
+func NewDevFoo() *Foo {
+	return &Foo{Name: "dev"}
+}
+
+var ProdSet = wire.NewSet(NewProdFoo, NewBar)
+
+var DevSet = wire.NewSet(NewDevFoo)
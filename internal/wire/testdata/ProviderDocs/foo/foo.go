@@ -0,0 +1,36 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"example.com/bar"
+	"github.com/google/wire"
+)
+
+func main() {
+	fmt.Println(injectGreeting())
+}
+
+type Greeting string
+
+// Set provides a greeting.
+var Set = wire.NewSet(bar.Set, provideGreeting)
+
+// provideGreeting builds a greeting for the configured name.
+func provideGreeting(name bar.Name) Greeting {
+	return Greeting("Hello, " + string(name))
+}
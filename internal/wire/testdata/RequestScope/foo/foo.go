@@ -0,0 +1,69 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	rc := &RequestContext{Path: "/users/gopher"}
+	h := injectHandler(rc)
+	fmt.Println(h.audit.line)
+	fmt.Println(h.user, h.trace)
+	fmt.Println("derivations:", rc.derivations)
+}
+
+// RequestContext is given to the injector once per request.
+type RequestContext struct {
+	Path        string
+	derivations int
+}
+
+type User string
+type TraceID string
+
+// Scope holds the values derived from a request.
+type Scope struct {
+	User  User
+	Trace TraceID
+}
+
+// provideScope derives the request-scoped values. Wire calls it once even
+// though several providers need its fields.
+func provideScope(rc *RequestContext) *Scope {
+	rc.derivations++
+	return &Scope{User: User(rc.Path[len("/users/"):]), Trace: "trace-1"}
+}
+
+type AuditLog struct {
+	line string
+}
+
+// provideAuditLog consumes the request context directly, as well as a value
+// derived from it.
+func provideAuditLog(rc *RequestContext, trace TraceID) *AuditLog {
+	return &AuditLog{line: fmt.Sprintf("%s %s", trace, rc.Path)}
+}
+
+type Handler struct {
+	audit *AuditLog
+	user  User
+	trace TraceID
+}
+
+func provideHandler(rc *RequestContext, audit *AuditLog, user User, trace TraceID) *Handler {
+	return &Handler{audit: audit, user: user, trace: trace}
+}
@@ -0,0 +1,31 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"example.com/app"
+	"example.com/handlers"
+	"github.com/google/wire"
+)
+
+func main() {
+	h := injectHandler()
+	fmt.Println(h.Repo.DB.DSN, h.Config.DSN)
+}
+
+// SuperSet reaches every set of the hierarchy through more than one path.
+var SuperSet = wire.NewSet(handlers.Set, app.Set)
@@ -0,0 +1,34 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package handlers imports the infra set both directly and through the app
+// set.
+package handlers
+
+import (
+	"example.com/app"
+	"example.com/infra"
+	"github.com/google/wire"
+)
+
+type Handler struct {
+	Repo   *app.Repo
+	Config *infra.Config
+}
+
+func NewHandler(r *app.Repo, c *infra.Config) *Handler {
+	return &Handler{Repo: r, Config: c}
+}
+
+var Set = wire.NewSet(infra.Set, app.Set, NewHandler)
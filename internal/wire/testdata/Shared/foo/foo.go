@@ -0,0 +1,66 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/wire"
+)
+
+func main() {
+	users, err := injectUsers()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	orders, err := injectOrders("orders")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(users.DB == orders.DB, users.DB.DSN, orders.Table)
+	fmt.Println("databases opened:", opened)
+}
+
+type DSN string
+
+type DB struct {
+	DSN DSN
+}
+
+type Table string
+
+type UserStore struct {
+	DB *DB
+}
+
+type OrderStore struct {
+	DB    *DB
+	Table Table
+}
+
+var opened int
+
+func provideDSN() DSN {
+	return "postgres://localhost"
+}
+
+func provideDB(dsn DSN) (*DB, error) {
+	opened++
+	return &DB{DSN: dsn}, nil
+}
+
+var DBSet = wire.NewSet(provideDSN, provideDB, wire.Shared(new(*DB)))
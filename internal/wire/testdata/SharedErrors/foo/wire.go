@@ -0,0 +1,33 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+)
+
+func injectConn(dsn DSN) *Conn {
+	// fail: the shared *Conn needs the injector argument.
+	wire.Build(provideConn, wire.Shared(new(*Conn)))
+	return nil
+}
+
+func injectCache() (*Cache, func()) {
+	// fail: the shared *Cache has a cleanup function.
+	wire.Build(provideCache, wire.Shared(new(*Cache)))
+	return nil, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	app := injectApp()
+	fmt.Println(app.Foo, app.Bar, app.note)
+	ptr := injectAppPtr()
+	fmt.Println(ptr.Foo, ptr.Bar, ptr.Skipped)
+	fmt.Println(injectServer().Name)
+}
+
+type Foo int
+type Bar string
+
+// App is built by filling in its fields, since nothing provides it.
+type App struct {
+	Foo     Foo
+	Bar     Bar
+	Skipped Foo `wire:"-"`
+	note    string
+}
+
+// Server is provided directly, so its fields are left alone.
+type Server struct {
+	Name string
+}
+
+func provideFoo() Foo {
+	return 41
+}
+
+func provideBar(foo Foo) Bar {
+	return Bar(fmt.Sprint("bar", foo))
+}
+
+func provideServer() Server {
+	return Server{Name: "direct"}
+}
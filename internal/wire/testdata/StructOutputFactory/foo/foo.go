@@ -0,0 +1,68 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	s, cleanup, err := initServices("db")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(s.Users.db == s.Orders.db, s.Users.db.dsn)
+	cleanup()
+	_, _, err = initServices("")
+	fmt.Println(err)
+}
+
+type Config string
+
+type DB struct {
+	dsn string
+}
+
+// OpenDB is called once for both services.
+func OpenDB(cfg Config) (*DB, func(), error) {
+	if cfg == "" {
+		return nil, nil, errors.New("no database configured")
+	}
+	fmt.Println("open", cfg)
+	return &DB{dsn: string(cfg)}, func() { fmt.Println("close", cfg) }, nil
+}
+
+type UserService struct {
+	db *DB
+}
+
+func NewUserService(db *DB) *UserService {
+	return &UserService{db: db}
+}
+
+type OrderService struct {
+	db *DB
+}
+
+func NewOrderService(db *DB) *OrderService {
+	return &OrderService{db: db}
+}
+
+type Services struct {
+	Users  *UserService
+	Orders *OrderService
+}
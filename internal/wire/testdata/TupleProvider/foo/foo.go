@@ -0,0 +1,70 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	c := injectConn()
+	c.w.Write("hello")
+	fmt.Println(c.r.Read())
+	right, err := injectRight()
+	fmt.Println(right, err)
+}
+
+type Buffer struct {
+	data []string
+}
+
+type Reader struct {
+	buf *Buffer
+}
+
+func (r *Reader) Read() string {
+	return fmt.Sprint(r.buf.data)
+}
+
+type Writer struct {
+	buf *Buffer
+}
+
+func (w *Writer) Write(s string) {
+	w.buf.data = append(w.buf.data, s)
+}
+
+// NewPipe returns the two ends of one buffer.
+func NewPipe() (*Reader, *Writer) {
+	buf := new(Buffer)
+	return &Reader{buf: buf}, &Writer{buf: buf}
+}
+
+type Conn struct {
+	r *Reader
+	w *Writer
+}
+
+func NewConn(r *Reader, w *Writer) *Conn {
+	return &Conn{r: r, w: w}
+}
+
+type Left string
+type Right string
+
+// NewPair returns both halves of a pair, or an error.
+func NewPair() (Left, Right, error) {
+	return "left", "right", nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bar
+
+import (
+	"errors"
+
+	"github.com/google/wire"
+)
+
+type Config struct {
+	Host string
+	Port int
+}
+
+type Port int
+
+func ProvidePort(p Port) int {
+	return int(p)
+}
+
+var Set = wire.NewSet(ProvidePort, wire.Struct(new(Config), "*"))
+
+// ValidateConfig reports whether cfg can be used to dial a server.
+func ValidateConfig(cfg Config) error {
+	if cfg.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"example.com/bar"
+)
+
+type Server struct {
+	Addr string
+}
+
+func provideServer(cfg bar.Config) (*Server, func()) {
+	return &Server{Addr: fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}, func() {
+		fmt.Println("cleanup server")
+	}
+}
+
+func validateServer(s *Server) error {
+	if s.Addr == ":0" {
+		return errors.New("no address")
+	}
+	return nil
+}
+
+func main() {
+	cfg, err := injectConfig("localhost", 8080)
+	fmt.Println(cfg, err)
+	_, err = injectConfig("localhost", 0)
+	fmt.Println(err)
+	_, cleanup, err := injectServer("", 0)
+	fmt.Println(cleanup == nil, err)
+	s, cleanup, err := injectServer("example.com", 80)
+	fmt.Println(s.Addr, err)
+	cleanup()
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errRefused = errors.New("connection refused")
+
+func main() {
+	_, err := injectApp()
+	fmt.Println(err)
+	fmt.Println(errors.Is(err, errRefused))
+}
+
+type DB struct{}
+
+type Server struct {
+	db *DB
+}
+
+type App struct {
+	srv *Server
+}
+
+func NewDB() (*DB, error) {
+	return nil, errRefused
+}
+
+func NewServer(db *DB) (*Server, error) {
+	return &Server{db: db}, nil
+}
+
+// NewApp builds its server with another injector, so the error names both
+// providers.
+func NewApp() (*App, error) {
+	srv, err := injectServer()
+	if err != nil {
+		return nil, err
+	}
+	return &App{srv: srv}, nil
+}
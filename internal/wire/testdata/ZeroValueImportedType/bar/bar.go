@@ -0,0 +1,48 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bar declares the types that the injectors in example.com/foo
+// return, so that their zero values must be qualified.
+package bar
+
+import "errors"
+
+type Config struct {
+	Name string
+}
+
+type Grid [2]int
+
+type Handler func() string
+
+func NewConfig(fail bool) (Config, error) {
+	if fail {
+		return Config{}, errors.New("no config")
+	}
+	return Config{Name: "config"}, nil
+}
+
+func NewGrid(fail bool) (Grid, error) {
+	if fail {
+		return Grid{}, errors.New("no grid")
+	}
+	return Grid{1, 2}, nil
+}
+
+func NewHandler(fail bool) (Handler, error) {
+	if fail {
+		return nil, errors.New("no handler")
+	}
+	return func() string { return "handler" }, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 The Wire Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//+build wireinject
+
+package main
+
+import (
+	"example.com/bar"
+	"github.com/google/wire"
+)
+
+func injectConfig(fail bool) (bar.Config, error) {
+	wire.Build(bar.NewConfig)
+	return bar.Config{}, nil
+}
+
+func injectGrid(fail bool) (bar.Grid, error) {
+	wire.Build(bar.NewGrid)
+	return bar.Grid{}, nil
+}
+
+func injectHandler(fail bool) (bar.Handler, error) {
+	wire.Build(bar.NewHandler)
+	return nil, nil
+}
@@ -22,12 +22,16 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
 	"go/format"
 	"go/printer"
 	"go/token"
 	"go/types"
 	"io/ioutil"
+	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,6 +40,7 @@ import (
 
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
 )
 
 // GenerateResult stores the result for a package from a call to Generate.
@@ -46,18 +51,128 @@ type GenerateResult struct {
 	// May be empty if there were errors.
 	OutputPath string
 	// Content is the gofmt'd source code that was generated. May be nil if
-	// there were errors during generation.
+	// there were errors during generation or if the package has no
+	// injectors. In the latter case, Warnings includes ErrNoInjectors if
+	// the package has files with the inject build tag.
 	Content []byte
 	// Errs is a slice of errors identified during generation.
 	Errs []error
+	// Warnings is a slice of problems that do not prevent generation, such
+	// as providers without doc comments when RequireProviderDocs is set.
+	Warnings []error
+	// Mode is the permission bits the output file should have, or zero to
+	// use the default permissions of new files. It is read-only when
+	// GenerateOptions.ReadOnly is set.
+	Mode os.FileMode
+
+	// removeStale is true if the package was loaded with files that have
+	// the inject build tag but declare no injectors, so that a file left
+	// at OutputPath by an earlier run is out of date.
+	removeStale bool
 }
 
-// Commit writes the generated file to disk.
+// ErrNoInjectors is reported in GenerateResult.Warnings for a package that
+// generates nothing, either because its files with the inject build tag
+// declare no injectors, or because no such files were loaded but an earlier
+// run left a generated file, which is then kept. Use errors.Is to recognize
+// it.
+var ErrNoInjectors = errors.New("no injectors found")
+
+// Commit writes the generated file to disk. It refuses to replace a file
+// that does not have a "// Code generated ... DO NOT EDIT." comment, so that
+// a hand-written file is never lost. If generation succeeded but produced
+// no output because the files with the inject build tag declare no
+// injectors, Commit removes the file left by an earlier run instead. The
+// file is kept if no such files were loaded, since they may only have been
+// left out by other build constraints.
 func (gen GenerateResult) Commit() error {
 	if len(gen.Content) == 0 {
+		if len(gen.Errs) > 0 || gen.OutputPath == "" || !gen.removeStale {
+			return nil
+		}
+		generated, err := isGeneratedFile(gen.OutputPath)
+		if os.IsNotExist(err) || err == nil && !generated {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return os.Remove(gen.OutputPath)
+	}
+	generated, err := isGeneratedFile(gen.OutputPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && !generated {
+		return fmt.Errorf("%s is not a generated file; refusing to overwrite it", gen.OutputPath)
+	}
+	if fi, err := os.Stat(gen.OutputPath); err == nil && fi.Mode().Perm()&0200 == 0 {
+		// A read-only file from an earlier run must be writable to replace it.
+		if err := os.Chmod(gen.OutputPath, fi.Mode().Perm()|0200); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(gen.OutputPath), 0777); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(gen.OutputPath, gen.Content, 0666); err != nil {
+		return err
+	}
+	if gen.Mode == 0 {
 		return nil
 	}
-	return ioutil.WriteFile(gen.OutputPath, gen.Content, 0666)
+	return os.Chmod(gen.OutputPath, gen.Mode)
+}
+
+// generatedComment matches the comment that marks a Go file as generated.
+// See https://golang.org/s/generatedcode.
+var generatedComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGeneratedFile reports whether the Go file at path has the comment that
+// marks generated files before its package clause.
+func isGeneratedFile(path string) (bool, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if generatedComment.MatchString(line) {
+			return true, nil
+		}
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+	}
+	return false, nil
+}
+
+// A FormatError is reported in GenerateResult.Errs when the generated
+// source cannot be formatted, which means that Wire produced invalid Go
+// rather than that the provider sets are wrong. GenerateResult.Content then
+// holds the unformatted source, which should be included in bug reports.
+type FormatError struct {
+	// Err is the error from go/format.
+	Err error
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("generated invalid Go source (this is a bug in Wire): %v", e.Err)
+}
+
+// Unwrap returns e.Err.
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// formatSource formats generated source with gofmt, returning a
+// *FormatError if it cannot be parsed.
+func formatSource(src []byte) ([]byte, error) {
+	fmtSrc, err := format.Source(src)
+	if err != nil {
+		return nil, &FormatError{Err: err}
+	}
+	return fmtSrc, nil
 }
 
 // GenerateOptions holds options for Generate.
@@ -66,6 +181,181 @@ type GenerateOptions struct {
 	Header           []byte
 	PrefixOutputFile string
 	Tags             string
+
+	// ConvertBasic allows a dependency on a defined type whose underlying
+	// type is a basic type, like "type Port int", to be satisfied by a
+	// conversion from its underlying type, and vice versa, when there is no
+	// provider for the type itself.
+	ConvertBasic bool
+
+	// Overrides lists provider functions that are added to every injector's
+	// provider set, replacing any other provider of the same types. This
+	// lets tests swap in fakes without editing the injector sources. Each
+	// function must be declared in a package imported (directly or
+	// transitively) by the package being generated.
+	Overrides []ProviderOverride
+
+	// AssertBindings adds a compile-time assertion, like
+	// "var _ Fooer = (*MyFoo)(nil)", after each injector for every interface
+	// binding the injector uses and every element it collects into a slice
+	// of interfaces, so that a binding that no longer holds fails to build.
+	AssertBindings bool
+
+	// MinimizeLiveVars reorders the independent provider calls in each
+	// injector so that fewer values are held in local variables at once.
+	// Calls still happen after the calls producing their arguments.
+	MinimizeLiveVars bool
+
+	// GivenFields lets a dependency be satisfied by an exported field of an
+	// injector argument whose type is a struct or a pointer to a struct, as
+	// long as exactly one such field has the needed type. The field is used
+	// only when no provider or argument has the type itself.
+	GivenFields bool
+
+	// GeneratorName is the tool name used in the "// Code generated by X.
+	// DO NOT EDIT." comment at the top of each generated file. It defaults
+	// to "Wire" and must not contain line breaks.
+	GeneratorName string
+
+	// AutoAddress lets a dependency on a pointer type *T, including the
+	// injector's output, be satisfied by taking the address of a T when
+	// there is no provider for *T. Unless AutoDeref is set, Wire never
+	// dereferences a *T to produce a T, since the pointer may be nil.
+	AutoAddress bool
+
+	// AutoDeref lets a dependency on a type T, including the injector's
+	// output, be satisfied by dereferencing a *T when there is no provider
+	// for T. The injector panics if the pointer is nil.
+	AutoDeref bool
+
+	// AssignableGivens lets a dependency on an interface type be satisfied
+	// by an injector argument whose type implements the interface, when
+	// nothing provides the interface itself. It is an error for more than
+	// one argument to implement the interface.
+	AssignableGivens bool
+
+	// AutoBind lets a dependency on an interface type be satisfied by the
+	// type provided by the injector's provider set that implements the
+	// interface, as if it had been bound with wire.Bind, when nothing
+	// provides the interface itself. It is an error for more than one
+	// provided type to implement the interface.
+	AutoBind bool
+
+	// StructOutput lets the output of an injector, if it is a named struct
+	// or a pointer to one, be built by filling in its exported fields from
+	// the provider set when nothing provides it, as if it had been given to
+	// wire.Struct with "*". Fields tagged with `wire:"-"` are left unset.
+	StructOutput bool
+
+	// AmbientContext adds a hint to the error for a context.Context that
+	// nothing provides, asking for a ctx parameter on the injector.
+	AmbientContext bool
+
+	// ErrorVarPerProvider gives the error returned by each provider its own
+	// variable named after the provider, like errServer for NewServer,
+	// instead of reusing a single err variable.
+	ErrorVarPerProvider bool
+
+	// SuggestProviders makes errors for types without a provider name the
+	// functions in the loaded packages that return the type, so that they
+	// can be added to the injector's provider set.
+	SuggestProviders bool
+
+	// CleanupOnError makes injectors that return a cleanup function return
+	// a non-nil one along with an error. It runs the cleanups of the values
+	// constructed before the failure, so it is safe to defer before checking
+	// the error, and the caller must call it. By default, the injector runs
+	// those cleanups itself and returns a nil cleanup function.
+	CleanupOnError bool
+
+	// WrapErrors makes injectors wrap each error returned by a provider
+	// with the provider's name, as in fmt.Errorf("NewDB: %w", err).
+	WrapErrors bool
+
+	// RequireProviderDocs reports a warning in GenerateResult.Warnings for
+	// each provider function used by an injector that has no doc comment.
+	RequireProviderDocs bool
+
+	// InjectorSets lists provider sets to add to injectors, as if they were
+	// passed to the injectors' wire.Build calls. It is usually read from a
+	// configuration file with ParseConfig. Each listed injector must exist.
+	InjectorSets []InjectorSets
+
+	// WarnUnusedArgs reports a warning in GenerateResult.Warnings for each
+	// injector argument that is not needed to produce the injector's
+	// output. Arguments named _ are not reported.
+	WarnUnusedArgs bool
+
+	// WarnBuiltinTypes reports a warning in GenerateResult.Warnings for
+	// each provider used by an injector that provides a predeclared type
+	// like string or int to another provider. Such values are better given
+	// a named type, like "type DSN string", that says what they are.
+	WarnBuiltinTypes bool
+
+	// RejectUnusedArgs reports the injector arguments found by
+	// WarnUnusedArgs as errors instead of warnings, whether or not
+	// WarnUnusedArgs is set.
+	RejectUnusedArgs bool
+
+	// ReadOnly sets GenerateResult.Mode so that the output file is written
+	// without write permission, to discourage editing it by hand.
+	ReadOnly bool
+
+	// StrictBindings reports an error when a type is provided both by an
+	// interface binding and by a provider of the interface type itself.
+	// By default, the provider takes precedence over the binding.
+	StrictBindings bool
+
+	// ErrorLabels makes injectors that can fail jump to a section at the
+	// end of the function to run cleanups and return errors, instead of
+	// repeating them after each provider that can fail. The injector's
+	// variables are then declared at its start. It cannot be combined with
+	// ErrorVarPerProvider.
+	ErrorLabels bool
+
+	// PlanComments adds a comment to each generated injector that lists
+	// its steps in order, with the provider of each and the type it
+	// produces, so that reviewers can check the wiring without running
+	// Wire.
+	PlanComments bool
+
+	// SourceComments adds a comment before each provider call in the
+	// generated injectors with the position of the provider, as in
+	// "// example.com/foo/foo.go:42", to help find the provider that a
+	// step of an injector calls, such as when stepping through it in a
+	// debugger.
+	SourceComments bool
+
+	// InjectTag is the build tag that the files declaring injectors have
+	// and that the generated files are built without. It defaults to
+	// "wireinject", and can be changed for projects that already use that
+	// tag for something else.
+	InjectTag string
+
+	// OutputPackage is the import path of a package to write the injectors
+	// to instead of the package that declares them, like
+	// "example.com/app/internal/wiring" for "example.com/app". The package
+	// is named after the last element of the path, and its directory is
+	// found by assuming that directories follow import paths, as they do in
+	// a module. The providers and types that the injectors use must then
+	// be exported. Only one package can be generated at a time.
+	OutputPackage string
+}
+
+// injectTag returns the build tag of the files declaring injectors.
+func (opts *GenerateOptions) injectTag() string {
+	if opts.InjectTag == "" {
+		return defaultInjectTag
+	}
+	return opts.InjectTag
+}
+
+// ProviderOverride identifies a provider function by the import path of the
+// package declaring it and its name. The function's parameters and results
+// are interpreted the same way as a provider passed to wire.NewSet.
+type ProviderOverride struct {
+	ImportPath string
+	FuncName   string
 }
 
 // Generate performs dependency injection for the packages that match the given
@@ -81,14 +371,53 @@ type GenerateOptions struct {
 //
 // Generate may return one or more errors if it failed to load the packages.
 func Generate(ctx context.Context, wd string, env []string, patterns []string, opts *GenerateOptions) ([]GenerateResult, []error) {
+	return generate(ctx, wd, env, patterns, opts, true)
+}
+
+// Check reports the errors that Generate would report for the packages that
+// match the given patterns, without writing and formatting the generated
+// files. It is meant for quick checks, as in pre-commit hooks. Like Load,
+// it also checks the package-level provider sets that no injector uses.
+func Check(ctx context.Context, wd string, env []string, patterns []string, opts *GenerateOptions) []error {
+	results, errs := generate(ctx, wd, env, patterns, opts, false)
+	for _, r := range results {
+		errs = append(errs, r.Errs...)
+	}
+	return errs
+}
+
+// generate implements Generate. If emit is false, it stops once the
+// injectors are checked, leaving the results without content.
+func generate(ctx context.Context, wd string, env []string, patterns []string, opts *GenerateOptions, emit bool) ([]GenerateResult, []error) {
 	if opts == nil {
 		opts = &GenerateOptions{}
 	}
-	pkgs, errs := load(ctx, wd, env, opts.Tags, patterns)
+	if strings.ContainsAny(opts.GeneratorName, "\r\n") {
+		return nil, []error{fmt.Errorf("generator name %q must not contain line breaks", opts.GeneratorName)}
+	}
+	if opts.ErrorLabels && opts.ErrorVarPerProvider {
+		return nil, []error{errors.New("ErrorLabels cannot be combined with ErrorVarPerProvider")}
+	}
+	if opts.OutputPackage != "" {
+		if name := path.Base(opts.OutputPackage); !token.IsIdentifier(name) {
+			return nil, []error{fmt.Errorf("output package %s: %q is not a valid package name", opts.OutputPackage, name)}
+		}
+	}
+	pkgs, errs := load(ctx, wd, env, opts.injectTag(), opts.Tags, patterns)
 	if len(errs) > 0 {
 		return nil, errs
 	}
+	if opts.OutputPackage != "" && len(pkgs) != 1 {
+		return nil, []error{fmt.Errorf("output package %s: found %d packages; want 1", opts.OutputPackage, len(pkgs))}
+	}
 	generated := make([]GenerateResult, len(pkgs))
+	// The provider sets processed for one package are reused for the
+	// others, since they all come from the same load.
+	var objects *objectCache
+	if len(pkgs) > 0 {
+		objects = newObjectCache(pkgs)
+		objects.strictBindings = opts.StrictBindings
+	}
 	for i, pkg := range pkgs {
 		generated[i].PkgPath = pkg.PkgPath
 		outDir, err := detectOutputDir(pkg.GoFiles)
@@ -96,19 +425,68 @@ func Generate(ctx context.Context, wd string, env []string, patterns []string, o
 			generated[i].Errs = append(generated[i].Errs, err)
 			continue
 		}
+		if opts.OutputPackage != "" {
+			outDir, err = packageDir(outDir, pkg.PkgPath, opts.OutputPackage)
+			if err != nil {
+				generated[i].Errs = append(generated[i].Errs, err)
+				continue
+			}
+		}
 		generated[i].OutputPath = filepath.Join(outDir, opts.PrefixOutputFile+"wire_gen.go")
-		g := newGen(pkg)
-		injectorFiles, errs := generateInjectors(g, pkg)
+		if opts.ReadOnly {
+			generated[i].Mode = 0444
+		}
+		g := newGen(pkg, opts)
+		injectorFiles, errs := generateInjectors(g, objects.forPackage(pkg), pkg, opts.Overrides)
+		generated[i].Warnings = g.warnings
+		if !emit {
+			errs = appendNewErrors(errs, providerSetErrors(objects.forPackage(pkg), pkg))
+		}
 		if len(errs) > 0 {
 			generated[i].Errs = errs
 			continue
 		}
+		buildExpr, err := injectorBuildConstraint(pkg.Fset, injectorFiles, opts.injectTag())
+		if err != nil {
+			generated[i].Errs = append(generated[i].Errs, err)
+			continue
+		}
+		if !emit {
+			continue
+		}
 		copyNonInjectorDecls(g, injectorFiles, pkg.TypesInfo)
-		goSrc := g.frame(opts.Tags)
+		if g.importsCollide() {
+			// The import names depend on the order the imports were first
+			// needed in. Generate the file again with the imports named in
+			// order of import path, so that unrelated changes to the
+			// injectors do not rename them.
+			prev := g
+			g = newGen(pkg, opts)
+			for _, path := range prev.importPaths() {
+				g.qualifyImport(prev.imports[path].pkgName, path)
+			}
+			generateInjectors(g, objects.forPackage(pkg), pkg, opts.Overrides)
+			copyNonInjectorDecls(g, injectorFiles, pkg.TypesInfo)
+		}
+		goSrc := g.frame(opts.Tags, buildExpr)
+		if len(goSrc) == 0 {
+			// The package has no injectors.
+			if f := injectTagFile(pkg, opts.injectTag()); f != nil {
+				generated[i].Warnings = append(generated[i].Warnings, notePosition(pkg.Fset.Position(f.Package),
+					fmt.Errorf("%w in files with the %s build tag", ErrNoInjectors, opts.injectTag())))
+				generated[i].removeStale = true
+			} else if ok, err := isGeneratedFile(generated[i].OutputPath); err == nil && ok {
+				// The files declaring the injectors may have been left
+				// out by the build tags or the platform.
+				generated[i].Warnings = append(generated[i].Warnings,
+					fmt.Errorf("%w in files with the %s build tag for the current build tags; leaving %s in place", ErrNoInjectors, opts.injectTag(), generated[i].OutputPath))
+			}
+			continue
+		}
 		if len(opts.Header) > 0 {
 			goSrc = append(opts.Header, goSrc...)
 		}
-		fmtSrc, err := format.Source(goSrc)
+		fmtSrc, err := formatSource(goSrc)
 		if err != nil {
 			// This is likely a bug from a poorly generated source file.
 			// Add an error but also the unformatted source.
@@ -121,6 +499,27 @@ func Generate(ctx context.Context, wd string, env []string, patterns []string, o
 	return generated, nil
 }
 
+// GenerateDir is like Generate, but generates the injectors for the single
+// package in the directory dir, without needing to know its import path.
+// The directory must be inside a module or a GOPATH workspace.
+func GenerateDir(ctx context.Context, dir string, env []string, opts *GenerateOptions) (GenerateResult, []error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return GenerateResult{}, []error{err}
+	}
+	results, errs := Generate(ctx, dir, env, []string{"."}, opts)
+	if len(errs) > 0 {
+		return GenerateResult{}, errs
+	}
+	if len(results) != 1 {
+		return GenerateResult{}, []error{fmt.Errorf("found %d packages in directory %s; want 1", len(results), dir)}
+	}
+	if p := results[0].PkgPath; p == "command-line-arguments" || strings.HasPrefix(p, "_/") {
+		return GenerateResult{}, []error{fmt.Errorf("directory %s is not inside a module or GOPATH, so its import path is unknown", dir)}
+	}
+	return results[0], nil
+}
+
 func detectOutputDir(paths []string) (string, error) {
 	if len(paths) == 0 {
 		return "", errors.New("no files to derive output directory from")
@@ -134,12 +533,165 @@ func detectOutputDir(paths []string) (string, error) {
 	return dir, nil
 }
 
-// generateInjectors generates the injectors for a given package.
-func generateInjectors(g *gen, pkg *packages.Package) (injectorFiles []*ast.File, _ []error) {
-	oc := newObjectCache([]*packages.Package{pkg})
+// packageDir returns the directory of the package with import path target,
+// given the directory dir of the package with import path pkgPath. The
+// directories are assumed to follow the import paths, so the two paths must
+// share a prefix.
+func packageDir(dir, pkgPath, target string) (string, error) {
+	from, to := strings.Split(pkgPath, "/"), strings.Split(target, "/")
+	n := 0
+	for n < len(from) && n < len(to) && from[n] == to[n] {
+		n++
+	}
+	if n == 0 {
+		return "", fmt.Errorf("output package %s does not share a path prefix with %s", target, pkgPath)
+	}
+	for range from[n:] {
+		dir = filepath.Dir(dir)
+	}
+	return filepath.Join(append([]string{dir}, to[n:]...)...), nil
+}
+
+// injectorBuildConstraint returns the build constraints that the files
+// declaring injectors have in addition to the inject tag, or nil if there
+// are none. All of the files must have the same additional constraints, and
+// the inject tag must be required by each of them.
+func injectorBuildConstraint(fset *token.FileSet, files []*ast.File, injectTag string) (constraint.Expr, error) {
+	var result constraint.Expr
+	for i, f := range files {
+		expr, err := fileBuildConstraint(f)
+		if err != nil {
+			return nil, notePosition(fset.Position(f.Package), err)
+		}
+		var extra constraint.Expr
+		if expr != nil {
+			extra, err = withoutTag(expr, injectTag)
+			if err != nil {
+				return nil, notePosition(fset.Position(f.Package), err)
+			}
+		}
+		if i == 0 {
+			result = extra
+			continue
+		}
+		if describeConstraint(extra) != describeConstraint(result) {
+			return nil, notePosition(fset.Position(f.Package), fmt.Errorf("injector files must have the same build constraints besides %s: found %s here and %s in %s", injectTag, describeConstraint(extra), describeConstraint(result), filepath.Base(fset.File(files[0].Pos()).Name())))
+		}
+	}
+	return result, nil
+}
+
+// injectTagFile returns the first file of pkg whose build constraints
+// mention injectTag and that is not ignored, or nil if there is none.
+func injectTagFile(pkg *packages.Package, injectTag string) *ast.File {
+	for _, f := range pkg.Syntax {
+		if ignoredFile(f) {
+			continue
+		}
+		expr, err := fileBuildConstraint(f)
+		if err != nil || expr == nil {
+			continue
+		}
+		found := false
+		expr.Eval(func(tag string) bool {
+			found = found || tag == injectTag
+			return true
+		})
+		if found {
+			return f
+		}
+	}
+	return nil
+}
+
+// fileBuildConstraint parses the build constraints at the top of f, or
+// returns nil if there are none. A //go:build line takes precedence over
+// // +build lines.
+func fileBuildConstraint(f *ast.File) (constraint.Expr, error) {
+	var goBuild, plusBuild constraint.Expr
+	for _, cg := range f.Comments {
+		if cg.Pos() >= f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				return nil, fmt.Errorf("parse build constraint: %v", err)
+			}
+			switch {
+			case constraint.IsGoBuild(c.Text):
+				goBuild = expr
+			case plusBuild == nil:
+				plusBuild = expr
+			default:
+				plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+			}
+		}
+	}
+	if goBuild != nil {
+		return goBuild, nil
+	}
+	return plusBuild, nil
+}
+
+// withoutTag removes tag from a conjunction of build constraints, returning
+// nil if nothing else remains.
+func withoutTag(expr constraint.Expr, tag string) (constraint.Expr, error) {
+	if and, ok := expr.(*constraint.AndExpr); ok {
+		x, err := withoutTag(and.X, tag)
+		if err != nil {
+			return nil, err
+		}
+		y, err := withoutTag(and.Y, tag)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case x == nil:
+			return y, nil
+		case y == nil:
+			return x, nil
+		}
+		return &constraint.AndExpr{X: x, Y: y}, nil
+	}
+	if t, ok := expr.(*constraint.TagExpr); ok && t.Tag == tag {
+		return nil, nil
+	}
+	if expr.Eval(func(t string) bool { return t != tag }) != expr.Eval(func(string) bool { return true }) {
+		return nil, fmt.Errorf("build constraint %q uses %s other than as a required tag", expr, tag)
+	}
+	return expr, nil
+}
+
+// describeConstraint formats a possibly nil build constraint for an error
+// message.
+func describeConstraint(expr constraint.Expr) string {
+	if expr == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%q", expr)
+}
+
+// generateInjectors generates the injectors for a given package, drawing
+// provider sets from oc.
+func generateInjectors(g *gen, oc *objectCache, pkg *packages.Package, overrides []ProviderOverride) (injectorFiles []*ast.File, _ []error) {
 	injectorFiles = make([]*ast.File, 0, len(pkg.Syntax))
+	overrideProviders, errs := oc.resolveOverrides(overrides)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	extraSets, errs := oc.resolveInjectorSets(pkg.PkgPath, g.opts.InjectorSets)
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	ec := new(errorCollector)
 	for _, f := range pkg.Syntax {
+		if ignoredFile(f) {
+			continue
+		}
 		for _, decl := range f.Decls {
 			fn, ok := decl.(*ast.FuncDecl)
 			if !ok {
@@ -148,11 +700,14 @@ func generateInjectors(g *gen, pkg *packages.Package) (injectorFiles []*ast.File
 			buildCall, err := findInjectorBuild(pkg.TypesInfo, fn)
 			if err != nil {
 				ec.add(err)
+				delete(extraSets, fn.Name.Name)
 				continue
 			}
 			if buildCall == nil {
 				continue
 			}
+			extra, configured := extraSets[fn.Name.Name]
+			delete(extraSets, fn.Name.Name)
 			if len(injectorFiles) == 0 || injectorFiles[len(injectorFiles)-1] != f {
 				// This is the first injector generated for this file.
 				// Write a file header.
@@ -176,10 +731,17 @@ func generateInjectors(g *gen, pkg *packages.Package) (injectorFiles []*ast.File
 				Pos:   fn.Pos(),
 			}
 			set, errs := oc.processNewSet(pkg.TypesInfo, pkg.PkgPath, buildCall, injectorArgs, "")
+			if len(errs) == 0 && (len(overrideProviders) > 0 || configured) {
+				set.Imports = append(set.Imports, extra...)
+				errs = oc.applyOverrides(set, overrideProviders)
+			}
 			if len(errs) > 0 {
 				ec.add(notePositionAll(g.pkg.Fset.Position(fn.Pos()), errs)...)
 				continue
 			}
+			if g.opts.RequireProviderDocs {
+				g.checkProviderDocs(oc, set)
+			}
 			if errs := g.inject(fn.Pos(), fn.Name.Name, sig, set, fn.Doc); len(errs) > 0 {
 				ec.add(errs...)
 				continue
@@ -192,6 +754,14 @@ func generateInjectors(g *gen, pkg *packages.Package) (injectorFiles []*ast.File
 			}
 		}
 	}
+	unknown := make([]string, 0, len(extraSets))
+	for name := range extraSets {
+		unknown = append(unknown, name)
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		ec.add(fmt.Errorf("config lists provider sets for %s.%s, which is not an injector", pkg.PkgPath, name))
+	}
 	if len(ec.errors) > 0 {
 		return nil, ec.errors
 	}
@@ -201,6 +771,27 @@ func generateInjectors(g *gen, pkg *packages.Package) (injectorFiles []*ast.File
 // copyNonInjectorDecls copies any non-injector declarations from the
 // given files into the generated output.
 func copyNonInjectorDecls(g *gen, files []*ast.File, info *types.Info) {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					g.copied[info.Defs[decl.Name]] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						g.copied[info.Defs[spec.Name]] = true
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							g.copied[info.Defs[name]] = true
+						}
+					}
+				}
+			}
+		}
+	}
 	for _, f := range files {
 		name := filepath.Base(g.pkg.Fset.File(f.Pos()).Name())
 		first := true
@@ -237,45 +828,95 @@ type importInfo struct {
 	// differs is true if the import is given an identifier that does not
 	// match the package's identifier.
 	differs bool
+	// pkgName is the package's identifier.
+	pkgName string
 }
 
 // gen is the file-wide generator state.
 type gen struct {
-	pkg         *packages.Package
+	pkg *packages.Package
+	// outPath and outName are the import path and name of the package the
+	// generated file belongs to, which is pkg unless OutputPackage is set.
+	outPath     string
+	outName     string
 	buf         bytes.Buffer
 	imports     map[string]importInfo
 	anonImports map[string]bool
 	values      map[ast.Expr]string
+	opts        *GenerateOptions
+	// asserted records the interface assertions already emitted, keyed by
+	// the assertion source.
+	asserted map[string]bool
+	// providerCandidates maps a type to the functions in the loaded
+	// packages that return it. It is built on first use by
+	// suggestProviders.
+	providerCandidates *typeutil.Map
+	// warnings holds the problems found that do not stop generation.
+	warnings []error
+	// docChecked records the providers checked by checkProviderDocs.
+	docChecked map[*Provider]bool
+	// renamed records the names given to injectors with //wire:name.
+	renamed map[string]bool
+	// shared maps a type declared with wire.Shared to its *sharedValue.
+	// sharedOrder lists the values in the order they were first needed.
+	shared      *typeutil.Map
+	sharedOrder []*sharedValue
+	// copied records the top-level objects of the declarations copied
+	// from injector files, which the generated file declares again.
+	copied map[types.Object]bool
 }
 
-func newGen(pkg *packages.Package) *gen {
+func newGen(pkg *packages.Package, opts *GenerateOptions) *gen {
+	outPath, outName := pkg.PkgPath, pkg.Name
+	if opts.OutputPackage != "" {
+		outPath, outName = opts.OutputPackage, path.Base(opts.OutputPackage)
+	}
 	return &gen{
 		pkg:         pkg,
+		outPath:     outPath,
+		outName:     outName,
 		anonImports: make(map[string]bool),
 		imports:     make(map[string]importInfo),
 		values:      make(map[ast.Expr]string),
+		opts:        opts,
+		asserted:    make(map[string]bool),
+		docChecked:  make(map[*Provider]bool),
+		renamed:     make(map[string]bool),
+		shared:      new(typeutil.Map),
+		copied:      make(map[types.Object]bool),
 	}
 }
 
 // frame bakes the built up source body into an unformatted Go source file.
-func (g *gen) frame(tags string) []byte {
+// The generated file is constrained to the negation of the inject tag and,
+// if it is not nil, buildExpr.
+func (g *gen) frame(tags string, buildExpr constraint.Expr) []byte {
 	if g.buf.Len() == 0 {
 		return nil
 	}
 	var buf bytes.Buffer
-	buf.WriteString("// Code generated by Wire. DO NOT EDIT.\n\n")
-	buf.WriteString("//+build !wireinject\n\n")
+	generator := g.opts.GeneratorName
+	if generator == "" {
+		generator = "Wire"
+	}
+	fmt.Fprintf(&buf, "// Code generated by %s. DO NOT EDIT.\n\n", generator)
+	var expr constraint.Expr = &constraint.NotExpr{X: &constraint.TagExpr{Tag: g.opts.injectTag()}}
+	if buildExpr != nil {
+		expr = &constraint.AndExpr{X: expr, Y: buildExpr}
+	}
+	fmt.Fprintf(&buf, "//go:build %s\n", expr)
+	if lines, err := constraint.PlusBuildLines(expr); err == nil {
+		for _, line := range lines {
+			buf.WriteString(line + "\n")
+		}
+	}
+	buf.WriteString("\n")
 	buf.WriteString("package ")
-	buf.WriteString(g.pkg.Name)
+	buf.WriteString(g.outName)
 	buf.WriteString("\n\n")
 	if len(g.imports) > 0 {
 		buf.WriteString("import (\n")
-		imps := make([]string, 0, len(g.imports))
-		for path := range g.imports {
-			imps = append(imps, path)
-		}
-		sort.Strings(imps)
-		for _, path := range imps {
+		for _, path := range g.importPaths() {
 			// Omit the local package identifier if it matches the package name.
 			info := g.imports[path]
 			if info.differs {
@@ -310,8 +951,31 @@ func (g *gen) inject(pos token.Pos, name string, sig *types.Signature, set *Prov
 		return []error{notePosition(g.pkg.Fset.Position(pos),
 			fmt.Errorf("inject %s: %v", name, err))}
 	}
+	if tn := g.unexportedType(sig); tn != nil {
+		return []error{notePosition(g.pkg.Fset.Position(pos),
+			fmt.Errorf("inject %s: %s.%s is not exported, so it cannot be used from package %s", name, tn.Pkg().Path(), tn.Name(), g.outPath))}
+	}
 	params := sig.Params()
-	calls, errs := solve(g.pkg.Fset, injectSig.out, params, set)
+	var suggest func(types.Type) string
+	if g.opts.SuggestProviders || g.opts.AmbientContext {
+		suggest = g.suggest
+	}
+	calls, errs := solve(g.pkg.Fset, injectSig.out, params, set, &solveOptions{
+		convertBasic:     g.opts.ConvertBasic,
+		givenFields:      g.opts.GivenFields,
+		autoAddress:      g.opts.AutoAddress,
+		autoDeref:        g.opts.AutoDeref,
+		assignableGivens: g.opts.AssignableGivens,
+		autoBind:         g.opts.AutoBind,
+		structOutput:     g.opts.StructOutput,
+		suggest:          suggest,
+	})
+	if len(errs) > 0 && set.empty() {
+		// The errors about missing providers would hide that the
+		// arguments to wire.Build were forgotten.
+		return []error{notePosition(g.pkg.Fset.Position(pos),
+			fmt.Errorf("inject %s: wire.Build has no providers; pass it the providers and provider sets to build %s from", name, types.TypeString(injectSig.out, nil)))}
+	}
 	if len(errs) > 0 {
 		return mapErrors(errs, func(e error) error {
 			if w, ok := e.(*wireErr); ok {
@@ -320,29 +984,61 @@ func (g *gen) inject(pos token.Pos, name string, sig *types.Signature, set *Prov
 			return notePosition(g.pkg.Fset.Position(pos), fmt.Errorf("inject %s: %v", name, e))
 		})
 	}
+	g.warnings = append(g.warnings, convertibleArgWarnings(g.pkg.Fset, name, calls, params)...)
+	if g.opts.WarnBuiltinTypes {
+		g.warnings = append(g.warnings, builtinTypeWarnings(g.pkg.Fset, name, calls, params.Len())...)
+	}
+	if g.opts.MinimizeLiveVars {
+		calls = reorderCalls(calls, params.Len())
+	}
+	if g.opts.WarnUnusedArgs || g.opts.RejectUnusedArgs {
+		var unused []error
+		for _, i := range unusedArgs(params, calls, injectSig.out, set) {
+			unused = append(unused, notePosition(g.pkg.Fset.Position(pos),
+				fmt.Errorf("inject %s: argument %s is not used", name, params.At(i).Name())))
+		}
+		if g.opts.RejectUnusedArgs && len(unused) > 0 {
+			return unused
+		}
+		g.warnings = append(g.warnings, unused...)
+	}
 	type pendingVar struct {
 		name     string
 		expr     ast.Expr
 		typeInfo *types.Info
 	}
 	var pendingVars []pendingVar
+	// An injector with a //wire:panic directive panics with the errors it
+	// cannot return.
+	panics := hasDirective(doc, "wire:panic") && !injectSig.err
+	canFail := injectSig.err || panics
 	ec := new(errorCollector)
 	for i := range calls {
 		c := &calls[i]
+		if c.kind == invokeCall && c.hasErr && !canFail {
+			ec.add(notePosition(
+				g.pkg.Fset.Position(c.pos),
+				fmt.Errorf("inject %s: %s returns error but injection not allowed to fail", name, c.name)))
+		}
 		if c.hasCleanup && !injectSig.cleanup {
 			ts := types.TypeString(c.out, nil)
 			ec.add(notePosition(
 				g.pkg.Fset.Position(pos),
 				fmt.Errorf("inject %s: provider for %s returns cleanup but injection does not return cleanup function", name, ts)))
 		}
-		if c.hasErr && !injectSig.err {
+		if c.kind != invokeCall && c.hasErr && !canFail {
 			ts := types.TypeString(c.out, nil)
 			ec.add(notePosition(
 				g.pkg.Fset.Position(pos),
 				fmt.Errorf("inject %s: provider for %s returns error but injection not allowed to fail", name, ts)))
 		}
+		if n := g.unexportedName(c); n != "" {
+			ec.add(notePosition(
+				g.pkg.Fset.Position(pos),
+				fmt.Errorf("inject %s: %s.%s is not exported, so it cannot be used from package %s", name, c.pkg.Path(), n, g.outPath)))
+		}
 		if c.kind == valueExpr {
-			if err := accessibleFrom(c.valueTypeInfo, c.valueExpr, g.pkg.PkgPath); err != nil {
+			if err := accessibleFrom(c.valueTypeInfo, c.valueExpr, g.outPath); err != nil {
 				// TODO(light): Display line number of value expression.
 				ts := types.TypeString(c.out, nil)
 				ec.add(notePosition(
@@ -362,29 +1058,359 @@ func (g *gen) inject(pos token.Pos, name string, sig *types.Signature, set *Prov
 			}
 		}
 	}
-	if len(ec.errors) > 0 {
-		return ec.errors
+	if shared := sharedTypes(set); len(shared) > 0 && len(ec.errors) == 0 {
+		var errs []error
+		calls, errs = g.shareCalls(calls, params, shared)
+		for _, err := range errs {
+			ec.add(notePosition(g.pkg.Fset.Position(pos), fmt.Errorf("inject %s: %v", name, err)))
+		}
+	}
+	funcName := name
+	if rename, dpos, ok := directiveValue(doc, "wire:name"); ok {
+		switch {
+		case !token.IsIdentifier(rename):
+			ec.add(notePosition(
+				g.pkg.Fset.Position(dpos),
+				fmt.Errorf("inject %s: %q in //wire:name is not a valid function name", name, rename)))
+		case rename != name && (g.nameInFileScope(rename) || g.renamed[rename]):
+			ec.add(notePosition(
+				g.pkg.Fset.Position(dpos),
+				fmt.Errorf("inject %s: cannot rename to %s: name already declared in package", name, rename)))
+		default:
+			funcName = rename
+			g.renamed[rename] = true
+		}
+	}
+	var validator *types.Func
+	if v, dpos, ok := directiveValue(doc, "wire:validate"); ok {
+		var err error
+		validator, err = g.validator(v, injectSig, canFail)
+		if err != nil {
+			ec.add(notePosition(g.pkg.Fset.Position(dpos), fmt.Errorf("inject %s: %v", name, err)))
+		}
+	}
+	must := hasDirective(doc, "wire:must") && injectSig.err
+	mustName := mustInjectorName(funcName)
+	if must && g.nameInFileScope(mustName) {
+		ec.add(notePosition(
+			g.pkg.Fset.Position(pos),
+			fmt.Errorf("inject %s: cannot generate %s: name already declared in package", name, mustName)))
+	}
+	if len(ec.errors) > 0 {
+		return ec.errors
+	}
+
+	// Perform one pass to collect all imports, followed by the real pass.
+	injectPass(funcName, sig, calls, set, doc, &injectorGen{
+		g:         g,
+		errVar:    disambiguate("err", g.nameInFileScope),
+		validator: validator,
+		panics:    panics,
+		discard:   true,
+	})
+	injectPass(funcName, sig, calls, set, doc, &injectorGen{
+		g:         g,
+		errVar:    disambiguate("err", g.nameInFileScope),
+		validator: validator,
+		panics:    panics,
+		discard:   false,
+	})
+	if must {
+		g.mustInjector(mustName, funcName, sig, injectSig)
+	}
+	g.writeShared()
+	if len(pendingVars) > 0 {
+		g.p("var (\n")
+		for _, pv := range pendingVars {
+			g.p("\t%s = ", pv.name)
+			g.writeAST(pv.typeInfo, pv.expr)
+			g.p("\n")
+		}
+		g.p(")\n\n")
+	}
+	if g.opts.AssertBindings {
+		g.bindingAssertions(injectSig.out, calls, set)
+	}
+	return nil
+}
+
+// unusedArgs returns the indices of the named injector parameters that are
+// not used to produce out. Parameters named _ are never reported.
+func unusedArgs(params *types.Tuple, calls []call, out types.Type, set *ProviderSet) []int {
+	used := make([]bool, params.Len())
+	if len(calls) == 0 {
+		used[set.For(out).Arg().Index] = true
+	}
+	for i := range calls {
+		for _, a := range calls[i].args {
+			if a < len(used) {
+				used[a] = true
+			}
+		}
+	}
+	var unused []int
+	for i := range used {
+		if name := params.At(i).Name(); !used[i] && name != "" && name != "_" {
+			unused = append(unused, i)
+		}
+	}
+	return unused
+}
+
+// mustInjector emits a function that calls the injector with the given name
+// and panics if it returns an error. The injector must return an error.
+func (g *gen) mustInjector(mustName, name string, sig *types.Signature, injectSig outputSignature) {
+	ig := &injectorGen{g: g}
+	params := sig.Params()
+	g.p("// %s is like %s but panics if %s returns an error.\n", mustName, name, name)
+	g.p("func %s(", mustName)
+	for i := 0; i < params.Len(); i++ {
+		if i > 0 {
+			g.p(", ")
+		}
+		pi := params.At(i)
+		a := pi.Name()
+		if a == "" || a == "_" {
+			a = typeVariableName(pi.Type(), "arg", unexport, ig.nameInInjector)
+		} else {
+			a = disambiguate(a, ig.nameInInjector)
+		}
+		ig.paramNames = append(ig.paramNames, a)
+		if sig.Variadic() && i == params.Len()-1 {
+			g.p("%s ...%s", a, types.TypeString(pi.Type().(*types.Slice).Elem(), g.qualifyPkg))
+		} else {
+			g.p("%s %s", a, types.TypeString(pi.Type(), g.qualifyPkg))
+		}
+	}
+	outTypeString := types.TypeString(injectSig.out, g.qualifyPkg)
+	if injectSig.cleanup {
+		g.p(") (%s, func()) {\n", outTypeString)
+	} else {
+		g.p(") %s {\n", outTypeString)
+	}
+	v := typeVariableName(injectSig.out, "v", unexport, ig.nameInInjector)
+	ig.localNames = append(ig.localNames, v)
+	results := []string{v}
+	if injectSig.cleanup {
+		cleanup := disambiguate("cleanup", ig.nameInInjector)
+		ig.cleanupNames = append(ig.cleanupNames, cleanup)
+		results = append(results, cleanup)
+	}
+	errVar := disambiguate("err", ig.nameInInjector)
+	args := strings.Join(ig.paramNames, ", ")
+	if sig.Variadic() {
+		args += "..."
+	}
+	g.p("\t%s, %s := %s(%s)\n", strings.Join(results, ", "), errVar, name, args)
+	g.p("\tif %s != nil {\n", errVar)
+	g.p("\t\tpanic(%s)\n", errVar)
+	g.p("\t}\n")
+	g.p("\treturn %s\n", strings.Join(results, ", "))
+	g.p("}\n\n")
+}
+
+// mustInjectorName returns the name of the panicking variant of the
+// injector with the given name, keeping the injector's visibility:
+// initApp becomes mustInitApp and InitApp becomes MustInitApp.
+func mustInjectorName(name string) string {
+	if ast.IsExported(name) {
+		return "Must" + name
+	}
+	return "must" + export(name)
+}
+
+// validator resolves the function named in a //wire:validate directive.
+// The name is either a function in the generated package or one qualified
+// by the name or import path of a package it imports, like
+// "config.Validate". The function must accept the injector's output and
+// return only an error, and the injector must be able to fail.
+func (g *gen) validator(name string, injectSig outputSignature, canFail bool) (*types.Func, error) {
+	if !canFail {
+		return nil, fmt.Errorf("validator %s returns error but injection not allowed to fail", name)
+	}
+	scope := g.pkg.Types.Scope()
+	sym := name
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		qual := name[:i]
+		sym = name[i+1:]
+		scope = nil
+		for _, imp := range g.pkg.Types.Imports() {
+			if imp.Path() == qual || imp.Name() == qual {
+				scope = imp.Scope()
+				break
+			}
+		}
+		if scope == nil {
+			return nil, fmt.Errorf("validator %s: no imported package %s", name, qual)
+		}
+	}
+	fn, ok := scope.Lookup(sym).(*types.Func)
+	if !ok || !fn.Exported() && fn.Pkg().Path() != g.outPath {
+		return nil, fmt.Errorf("validator %s is not a function", name)
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.TypeParams().Len() > 0 || sig.Params().Len() != 1 || sig.Variadic() ||
+		sig.Results().Len() != 1 || !types.Identical(sig.Results().At(0).Type(), errorType) {
+		return nil, fmt.Errorf("validator %s must have the signature func(%s) error", name, types.TypeString(injectSig.out, nil))
+	}
+	if in := sig.Params().At(0).Type(); !types.AssignableTo(injectSig.out, in) {
+		return nil, fmt.Errorf("validator %s accepts %s, not %s", name, types.TypeString(in, nil), types.TypeString(injectSig.out, nil))
+	}
+	return fn, nil
+}
+
+// directiveValue returns the argument of a line comment in the comment group
+// like "//wire:name NewServer" for the given directive, if there is one,
+// along with the position of the comment so that errors about the argument
+// point at its line rather than at the start of the comment group.
+func directiveValue(doc *ast.CommentGroup, directive string) (string, token.Pos, bool) {
+	if doc == nil {
+		return "", token.NoPos, false
+	}
+	for _, c := range doc.List {
+		fields := strings.Fields(strings.TrimPrefix(c.Text, "//"))
+		if strings.HasPrefix(c.Text, "//"+directive) && len(fields) > 0 && fields[0] == directive {
+			return strings.Join(fields[1:], " "), c.Slash, true
+		}
+	}
+	return "", token.NoPos, false
+}
+
+// hasDirective reports whether the comment group contains a line comment
+// consisting of the given directive, like "//wire:must".
+func hasDirective(doc *ast.CommentGroup, directive string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == "//"+directive {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredFile reports whether f has a //wire:ignore directive before its
+// package clause. Wire does not look for injectors in such files, even if
+// they have the wireinject build tag.
+func ignoredFile(f *ast.File) bool {
+	for _, cg := range f.Comments {
+		if cg.Pos() >= f.Package {
+			break
+		}
+		if hasDirective(cg, "wire:ignore") {
+			return true
+		}
+	}
+	return false
+}
+
+// bindingAssertions emits an assertion that the concrete type satisfies the
+// interface for each interface binding used to produce out from calls, and
+// for each element collected into a slice of interfaces.
+// Assertions already emitted for an earlier injector are skipped.
+func (g *gen) bindingAssertions(out types.Type, calls []call, set *ProviderSet) {
+	var lines []string
+	add := func(iface, concrete types.Type) {
+		line := fmt.Sprintf("_ %s = %s", types.TypeString(iface, g.qualifyPkg), typedZeroValue(concrete, g.qualifyPkg))
+		if !g.asserted[line] {
+			g.asserted[line] = true
+			lines = append(lines, line)
+		}
+	}
+	needed := []types.Type{out}
+	for i := range calls {
+		c := &calls[i]
+		if c.kind == collectExpr {
+			// Assert that each collected element implements the
+			// element interface.
+			elem := c.out.Underlying().(*types.Slice).Elem()
+			if !types.IsInterface(elem) {
+				continue
+			}
+			for _, t := range c.ins {
+				if !types.Identical(t, elem) {
+					add(elem, t)
+				}
+			}
+			continue
+		}
+		needed = append(needed, c.ins...)
+	}
+	for _, t := range needed {
+		pv := set.For(t)
+		if pv.IsNil() || types.Identical(pv.Type(), t) {
+			continue
+		}
+		add(t, pv.Type())
 	}
+	if len(lines) == 0 {
+		return
+	}
+	g.p("var (\n")
+	for _, line := range lines {
+		g.p("\t%s\n", line)
+	}
+	g.p(")\n\n")
+}
 
-	// Perform one pass to collect all imports, followed by the real pass.
-	injectPass(name, sig, calls, set, doc, &injectorGen{
-		g:       g,
-		errVar:  disambiguate("err", g.nameInFileScope),
-		discard: true,
-	})
-	injectPass(name, sig, calls, set, doc, &injectorGen{
-		g:       g,
-		errVar:  disambiguate("err", g.nameInFileScope),
-		discard: false,
-	})
-	if len(pendingVars) > 0 {
-		g.p("var (\n")
-		for _, pv := range pendingVars {
-			g.p("\t%s = ", pv.name)
-			g.writeAST(pv.typeInfo, pv.expr)
-			g.p("\n")
+// unexportedName returns the name of a provider, field or method used by c
+// that is unexported and declared in a package other than the generated
+// one, or the empty string if there is none.
+func (g *gen) unexportedName(c *call) string {
+	if c.pkg == nil || c.pkg.Path() == g.outPath || c.shared {
+		return ""
+	}
+	switch c.kind {
+	case funcProviderCall, structProvider, selectorExpr, invokeCall:
+	default:
+		return ""
+	}
+	if !token.IsExported(c.name) {
+		return c.name
+	}
+	for _, f := range c.fieldNames {
+		if !token.IsExported(f) {
+			return f
+		}
+	}
+	return ""
+}
+
+// unexportedType returns an unexported type from another package than the
+// generated one that t refers to, or nil if there is none.
+func (g *gen) unexportedType(t types.Type) *types.TypeName {
+	switch t := t.(type) {
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && obj.Pkg().Path() != g.outPath && !obj.Exported() {
+			return obj
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if tn := g.unexportedType(t.TypeArgs().At(i)); tn != nil {
+				return tn
+			}
+		}
+	case *types.Pointer:
+		return g.unexportedType(t.Elem())
+	case *types.Slice:
+		return g.unexportedType(t.Elem())
+	case *types.Array:
+		return g.unexportedType(t.Elem())
+	case *types.Chan:
+		return g.unexportedType(t.Elem())
+	case *types.Map:
+		if tn := g.unexportedType(t.Key()); tn != nil {
+			return tn
+		}
+		return g.unexportedType(t.Elem())
+	case *types.Signature:
+		for _, tuple := range []*types.Tuple{t.Params(), t.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if tn := g.unexportedType(tuple.At(i).Type()); tn != nil {
+					return tn
+				}
+			}
 		}
-		g.p(")\n\n")
 	}
 	return nil
 }
@@ -401,10 +1427,12 @@ func (g *gen) rewritePkgRefs(info *types.Info, node ast.Node) ast.Node {
 		case *ast.Ident:
 			// This is an unqualified identifier (qualified identifiers are peeled off below).
 			obj := info.ObjectOf(node)
-			if obj == nil {
+			if obj == nil || info.Defs[node] != nil || g.copied[obj] {
+				// Declaring identifiers and references to copied
+				// declarations stay unqualified.
 				return false
 			}
-			if pkg := obj.Pkg(); pkg != nil && obj.Parent() == pkg.Scope() && pkg.Path() != g.pkg.PkgPath {
+			if pkg := obj.Pkg(); pkg != nil && obj.Parent() == pkg.Scope() && pkg.Path() != g.outPath {
 				// An identifier from either a dot import or read from a different package.
 				newPkgID := g.qualifyImport(pkg.Name(), pkg.Path())
 				c.Replace(&ast.SelectorExpr{
@@ -523,7 +1551,7 @@ func (g *gen) qualifiedID(pkgName, pkgPath, sym string) string {
 }
 
 func (g *gen) qualifyImport(name, path string) string {
-	if path == g.pkg.PkgPath {
+	if path == g.outPath {
 		return ""
 	}
 	// TODO(light): This is depending on details of the current loader.
@@ -543,10 +1571,34 @@ func (g *gen) qualifyImport(name, path string) string {
 	g.imports[unvendored] = importInfo{
 		name:    newName,
 		differs: newName != name,
+		pkgName: name,
 	}
 	return newName
 }
 
+// importPaths returns the paths of the imports in sorted order.
+func (g *gen) importPaths() []string {
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// importsCollide reports whether two imports have the same package
+// identifier, so that one of them was given another name.
+func (g *gen) importsCollide() bool {
+	seen := make(map[string]bool)
+	for _, info := range g.imports {
+		if seen[info.pkgName] {
+			return true
+		}
+		seen[info.pkgName] = true
+	}
+	return false
+}
+
 func (g *gen) nameInFileScope(name string) bool {
 	for _, other := range g.imports {
 		if other.name == name {
@@ -558,6 +1610,13 @@ func (g *gen) nameInFileScope(name string) bool {
 			return true
 		}
 	}
+	for _, sv := range g.sharedOrder {
+		for _, other := range sharedNames(sv.name) {
+			if other == name {
+				return true
+			}
+		}
+	}
 	_, obj := g.pkg.Types.Scope().LookupParent(name, token.NoPos)
 	return obj != nil
 }
@@ -578,6 +1637,30 @@ type injectorGen struct {
 	localNames   []string
 	cleanupNames []string
 	errVar       string
+	// errNames holds the error variables of the calls so far when each
+	// provider call has its own error variable, and the variables for
+	// errors whose type is not error.
+	errNames []string
+	// typedErrNames holds the variables declared up front when errLabels
+	// is set for the errors of calls whose error type is not error.
+	typedErrNames map[*call]string
+	// resultNames holds the variables, by the index of their step, for the
+	// results of provider calls that return several values, which are
+	// named when the provider is called.
+	resultNames map[int]string
+	// validator is called on the injector's output before it is returned,
+	// if set by a //wire:validate directive.
+	validator *types.Func
+	// panics is true if the injector panics with errors instead of
+	// returning them, as set by a //wire:panic directive.
+	panics bool
+	// errLabels is true if the injector's variables are declared up front
+	// and errors are returned by jumping to labels at its end. failLabels
+	// records the labels used, by the number of cleanups each one runs, and
+	// declaredCleanups holds the cleanup variables in order of their calls.
+	errLabels        bool
+	failLabels       map[int]bool
+	declaredCleanups []string
 
 	// discard causes ig.p and ig.writeAST to no-op. Useful to run
 	// generation for side-effects like filling in g.imports.
@@ -598,6 +1681,12 @@ func injectPass(name string, sig *types.Signature, calls []call, set *ProviderSe
 			ig.p("%s\n", c.Text)
 		}
 	}
+	if ig.g.opts.PlanComments && len(calls) > 0 {
+		if doc != nil {
+			ig.p("//\n")
+		}
+		ig.planComment(calls)
+	}
 	ig.p("func %s(", name)
 	for i := 0; i < params.Len(); i++ {
 		if i > 0 {
@@ -630,28 +1719,80 @@ func injectPass(name string, sig *types.Signature, calls []call, set *ProviderSe
 	default:
 		ig.p(") %s {\n", outTypeString)
 	}
+	ig.errLabels = ig.g.opts.ErrorLabels && injectSig.err && (ig.validator != nil || anyErr(calls))
+	if ig.errLabels {
+		ig.declareLocals(calls)
+	}
 	for i := range calls {
 		c := &calls[i]
-		lname := typeVariableName(c.out, "v", unexport, ig.nameInInjector)
-		ig.localNames = append(ig.localNames, lname)
+		if c.kind == addressExpr && i == len(calls)-1 {
+			// The address is taken in the return statement.
+			break
+		}
+		var lname string
+		if ig.errLabels {
+			lname = ig.localNames[i]
+		} else {
+			switch {
+			case c.kind == invokeCall:
+				// Nothing is assigned.
+			case c.kind == resultExpr:
+				lname = ig.resultNames[i]
+			case c.outs != nil && !firstResultUsed(calls, params.Len(), i):
+				lname = "_"
+			default:
+				lname = ig.localName(c)
+			}
+			ig.localNames = append(ig.localNames, lname)
+		}
+		if ig.g.opts.SourceComments && c.pos.IsValid() {
+			ig.p("\t// %s\n", ig.g.sourcePosition(c.pkg, c.pos))
+		}
 		switch c.kind {
 		case structProvider:
 			ig.structProviderCall(lname, c)
 		case funcProviderCall:
+			if c.outs != nil {
+				lname = ig.tupleNames(calls, i, lname)
+			}
 			ig.funcProviderCall(lname, c, injectSig)
+		case resultExpr:
+			// Assigned by the provider call that returns it.
 		case valueExpr:
 			ig.valueExpr(lname, c)
 		case selectorExpr:
 			ig.fieldExpr(lname, c)
+		case convertExpr:
+			ig.convertExpr(lname, c)
+		case addressExpr:
+			ig.addressExpr(lname, c)
+		case derefExpr:
+			ig.derefExpr(lname, c)
+		case collectExpr:
+			ig.collectExpr(lname, c)
+		case invokeCall:
+			ig.invokeCall(c, injectSig)
 		default:
 			panic("unknown kind")
 		}
 	}
+	var result string
 	if len(calls) == 0 {
-		ig.p("\treturn %s", ig.paramNames[set.For(injectSig.out).Arg().Index])
+		result = ig.paramNames[set.For(injectSig.out).Arg().Index]
+	} else if last := calls[len(calls)-1]; last.kind == addressExpr {
+		if a := last.args[0]; a < len(ig.paramNames) {
+			result = "&" + ig.paramNames[a]
+		} else {
+			result = "&" + ig.localNames[a-len(ig.paramNames)]
+		}
 	} else {
-		ig.p("\treturn %s", ig.localNames[len(calls)-1])
+		result = ig.localNames[len(calls)-1]
 	}
+	if v := ig.validator; v != nil {
+		ig.p("\tif %s %s %s(%s); %s != nil {\n", ig.errVar, ig.define(), ig.g.qualifiedID(v.Pkg().Name(), v.Pkg().Path(), v.Name()), result, ig.errVar)
+		ig.errReturn(ig.errVar, v.Name(), len(ig.cleanupNames), injectSig)
+	}
+	ig.p("\treturn %s", result)
 	if injectSig.cleanup {
 		ig.p(", func() {\n")
 		for i := len(ig.cleanupNames) - 1; i >= 0; i-- {
@@ -662,54 +1803,411 @@ func injectPass(name string, sig *types.Signature, calls []call, set *ProviderSe
 	if injectSig.err {
 		ig.p(", nil")
 	}
-	ig.p("\n}\n\n")
+	ig.p("\n")
+	if ig.errLabels {
+		ig.failSection(injectSig)
+	}
+	ig.p("}\n\n")
+}
+
+// planComment writes a comment that lists calls, the steps of an injector.
+func (ig *injectorGen) planComment(calls []call) {
+	qf := func(pkg *types.Package) string {
+		if pkg.Path() == ig.g.outPath {
+			return ""
+		}
+		return pkg.Name()
+	}
+	ig.p("// Steps, in order:\n")
+	n := 0
+	for i := range calls {
+		c := &calls[i]
+		if c.kind == resultExpr {
+			// Listed with the provider call that returns it.
+			continue
+		}
+		var label string
+		switch c.kind {
+		case funcProviderCall, invokeCall:
+			switch {
+			case c.method:
+				label = fmt.Sprintf("(%s).%s", types.TypeString(c.ins[0], qf), c.name)
+			case c.shared || qf(c.pkg) == "":
+				label = c.name
+			default:
+				label = qf(c.pkg) + "." + c.name
+			}
+			if c.kind == invokeCall {
+				label = "invoke " + label
+			}
+		case structProvider:
+			t := c.out
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			label = "struct " + types.TypeString(t, qf)
+		default:
+			label = callLabel(c)
+		}
+		switch {
+		case c.outs != nil:
+			label += tupleLabel(calls, len(ig.paramNames), i, qf)
+		case c.out != nil:
+			label += " -> " + types.TypeString(c.out, qf)
+		}
+		n++
+		ig.p("//  %d. %s\n", n, label)
+	}
+}
+
+// tupleLabel describes the results of calls[i], a provider that returns
+// several values, that the injector uses, naming their positions when
+// others are discarded, as in " (result 2) -> *B".
+func tupleLabel(calls []call, numGiven, i int, qf types.Qualifier) string {
+	c := &calls[i]
+	used := make([]bool, len(c.outs))
+	used[0] = firstResultUsed(calls, numGiven, i)
+	for _, r := range calls[i+1:] {
+		if r.kind == resultExpr && r.args[0] == numGiven+i {
+			used[r.result] = true
+		}
+	}
+	var nums, outs []string
+	for k, t := range c.outs {
+		if used[k] {
+			nums = append(nums, strconv.Itoa(k+1))
+			outs = append(outs, types.TypeString(t, qf))
+		}
+	}
+	label := " -> " + strings.Join(outs, ", ")
+	switch {
+	case len(nums) == len(c.outs):
+		return label
+	case len(nums) == 1:
+		return " (result " + nums[0] + ")" + label
+	default:
+		return " (results " + strings.Join(nums, ", ") + ")" + label
+	}
+}
+
+// sourcePosition returns the position pos in package pkg as the import path
+// of pkg followed by the file name and line, which unlike the file's path
+// does not depend on where the package is.
+func (g *gen) sourcePosition(pkg *types.Package, pos token.Pos) string {
+	position := g.pkg.Fset.Position(pos)
+	return fmt.Sprintf("%s/%s:%d", pkg.Path(), filepath.Base(position.Filename), position.Line)
+}
+
+// localName returns the name of the variable holding the result of c.
+func (ig *injectorGen) localName(c *call) string {
+	if slice, ok := c.out.(*types.Slice); ok && c.kind == collectExpr {
+		// Name unnamed collections after their elements.
+		return typeVariableName(slice.Elem(), "v", func(name string) string { return unexport(name) + "s" }, ig.nameInInjector)
+	}
+	return typeVariableName(c.out, "v", unexport, ig.nameInInjector)
+}
+
+// firstResultUsed reports whether the first result of calls[i] is used by
+// a later step or returned by the injector.
+func firstResultUsed(calls []call, numGiven, i int) bool {
+	if i == len(calls)-1 {
+		return true
+	}
+	for _, c := range calls[i+1:] {
+		if c.kind == resultExpr {
+			continue
+		}
+		for _, a := range c.args {
+			if a == numGiven+i {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tupleNames returns the variables to assign the results of calls[i], a
+// provider that returns several values, to. The first result is assigned
+// to lname, and the results that no step uses are discarded.
+func (ig *injectorGen) tupleNames(calls []call, i int, lname string) string {
+	names := make([]string, len(calls[i].outs))
+	for k := range names {
+		names[k] = "_"
+	}
+	names[0] = lname
+	for j := i + 1; j < len(calls); j++ {
+		c := &calls[j]
+		if c.kind != resultExpr || c.args[0] != len(ig.paramNames)+i {
+			continue
+		}
+		if ig.errLabels {
+			names[c.result] = ig.localNames[j]
+			continue
+		}
+		name := ig.localName(c)
+		if ig.resultNames == nil {
+			ig.resultNames = make(map[int]string)
+		}
+		ig.resultNames[j] = name
+		names[c.result] = name
+	}
+	return strings.Join(names, ", ")
+}
+
+// anyErr reports whether any of calls can return an error.
+func anyErr(calls []call) bool {
+	for _, c := range calls {
+		if c.hasErr {
+			return true
+		}
+	}
+	return false
+}
+
+// declareLocals names the variables for the results of calls and writes
+// a var declaration for them, so that the jumps to the labels at the end
+// of the injector do not skip any declarations.
+func (ig *injectorGen) declareLocals(calls []call) {
+	ig.p("\tvar (\n")
+	for i := range calls {
+		c := &calls[i]
+		if c.kind == addressExpr && i == len(calls)-1 {
+			break
+		}
+		if c.kind == invokeCall {
+			ig.localNames = append(ig.localNames, "")
+			continue
+		}
+		if c.outs != nil && !firstResultUsed(calls, len(ig.paramNames), i) {
+			ig.localNames = append(ig.localNames, "_")
+		} else {
+			lname := ig.localName(c)
+			ig.localNames = append(ig.localNames, lname)
+			ig.p("\t\t%s %s\n", lname, types.TypeString(c.out, ig.g.qualifyPkg))
+		}
+		if c.hasCleanup {
+			cname := disambiguate("cleanup", func(name string) bool {
+				for _, d := range ig.declaredCleanups {
+					if d == name {
+						return true
+					}
+				}
+				return ig.nameInInjector(name)
+			})
+			ig.declaredCleanups = append(ig.declaredCleanups, cname)
+			ig.p("\t\t%s func()\n", cname)
+		}
+		if c.errType != nil {
+			ename := disambiguate(providerErrVarName(c.name), ig.nameInInjector)
+			if ig.typedErrNames == nil {
+				ig.typedErrNames = make(map[*call]string)
+			}
+			ig.typedErrNames[c] = ename
+			ig.errNames = append(ig.errNames, ename)
+			ig.p("\t\t%s %s\n", ename, types.TypeString(c.errType, ig.g.qualifyPkg))
+		}
+	}
+	ig.p("\t\t%s error\n", ig.errVar)
+	ig.p("\t)\n")
+}
+
+// define returns the operator that assigns the results of a step to its
+// variables, which are declared up front when errLabels is set.
+func (ig *injectorGen) define() string {
+	if ig.errLabels {
+		return "="
+	}
+	return ":="
+}
+
+// failLabel returns the label that runs the first n cleanups and returns
+// the error.
+func failLabel(n int) string {
+	if n == 0 {
+		return "fail"
+	}
+	return fmt.Sprintf("fail%d", n)
+}
+
+// failSection writes the labels that the injector jumps to when a step
+// fails. Unless CleanupOnError is set, the labels fall through to each
+// other, running the cleanups in reverse order before the single return.
+func (ig *injectorGen) failSection(injectSig outputSignature) {
+	last := 0
+	for n := range ig.failLabels {
+		if n > last {
+			last = n
+		}
+	}
+	zero := zeroValue(injectSig.out, ig.g.qualifyPkg)
+	if injectSig.cleanup && ig.g.opts.CleanupOnError {
+		for n := last; n >= 0; n-- {
+			if !ig.failLabels[n] {
+				continue
+			}
+			ig.p("%s:\n", failLabel(n))
+			ig.p("\treturn %s, func() {", zero)
+			if n > 0 {
+				ig.p("\n")
+				for i := n - 1; i >= 0; i-- {
+					ig.p("\t\t%s()\n", ig.cleanupNames[i])
+				}
+				ig.p("\t")
+			}
+			ig.p("}, %s\n", ig.errVar)
+		}
+		return
+	}
+	for n := last; n >= 0; n-- {
+		if ig.failLabels[n] {
+			ig.p("%s:\n", failLabel(n))
+		}
+		if n > 0 {
+			ig.p("\t%s()\n", ig.cleanupNames[n-1])
+		}
+	}
+	ig.p("\treturn %s", zero)
+	if injectSig.cleanup {
+		ig.p(", nil")
+	}
+	ig.p(", %s\n", ig.errVar)
 }
 
 func (ig *injectorGen) funcProviderCall(lname string, c *call, injectSig outputSignature) {
 	ig.p("\t%s", lname)
 	prevCleanup := len(ig.cleanupNames)
 	if c.hasCleanup {
-		cname := disambiguate("cleanup", ig.nameInInjector)
+		var cname string
+		if ig.errLabels {
+			cname = ig.declaredCleanups[prevCleanup]
+		} else {
+			cname = disambiguate("cleanup", ig.nameInInjector)
+		}
 		ig.cleanupNames = append(ig.cleanupNames, cname)
 		ig.p(", %s", cname)
 	}
+	errVar := ig.errVar
+	switch {
+	case c.errType != nil && ig.errLabels:
+		errVar = ig.typedErrNames[c]
+	case c.errType != nil || c.hasErr && ig.g.opts.ErrorVarPerProvider:
+		// An error of another type is kept in a variable of its own, so
+		// that a nil pointer is not mistaken for a non-nil error.
+		errVar = disambiguate(providerErrVarName(c.name), ig.nameInInjector)
+		ig.errNames = append(ig.errNames, errVar)
+	}
 	if c.hasErr {
-		ig.p(", %s", ig.errVar)
+		ig.p(", %s", errVar)
 	}
-	ig.p(" := ")
-	ig.p("%s(", ig.g.qualifiedID(c.pkg.Name(), c.pkg.Path(), c.name))
-	for i, a := range c.args {
+	ig.p(" %s ", ig.define())
+	args := c.args
+	switch {
+	case c.method:
+		ig.p("%s.%s", ig.argName(args[0]), c.name)
+		args = args[1:]
+	case c.shared:
+		// The function is written to the generated file.
+		ig.p("%s", c.name)
+	default:
+		ig.p("%s", ig.g.qualifiedID(c.pkg.Name(), c.pkg.Path(), c.name))
+	}
+	if len(c.typeArgs) > 0 {
+		ig.p("[")
+		for i, t := range c.typeArgs {
+			if i > 0 {
+				ig.p(", ")
+			}
+			ig.p("%s", types.TypeString(t, ig.g.qualifyPkg))
+		}
+		ig.p("]")
+	}
+	ig.p("(")
+	for i, a := range args {
 		if i > 0 {
 			ig.p(", ")
 		}
-		if a < len(ig.paramNames) {
-			ig.p("%s", ig.paramNames[a])
-		} else {
-			ig.p("%s", ig.localNames[a-len(ig.paramNames)])
-		}
+		ig.p("%s", ig.argName(a))
 	}
 	if c.varargs {
 		ig.p("...")
 	}
 	ig.p(")\n")
 	if c.hasErr {
-		ig.p("\tif %s != nil {\n", ig.errVar)
+		ig.p("\tif %s != nil {\n", errVar)
+		wrapName := c.name
+		if c.shared {
+			wrapName = ""
+		}
+		ig.errReturn(errVar, wrapName, prevCleanup, injectSig)
+	}
+}
+
+// argName returns the name of the variable holding the value at index a,
+// which is either an injector parameter or the result of a previous call.
+func (ig *injectorGen) argName(a int) string {
+	if a < len(ig.paramNames) {
+		return ig.paramNames[a]
+	}
+	return ig.localNames[a-len(ig.paramNames)]
+}
+
+// errReturn writes the body of the if statement that returns the non-nil
+// error in errVar from the function or validator with the given name. The
+// first prevCleanup cleanups are the ones for the values built so far.
+func (ig *injectorGen) errReturn(errVar, name string, prevCleanup int, injectSig outputSignature) {
+	errExpr := errVar
+	if ig.g.opts.WrapErrors && name != "" {
+		errExpr = fmt.Sprintf("%s(%q, %s)", ig.g.qualifiedID("fmt", "fmt", "Errorf"), name+": %w", errVar)
+	}
+	if ig.errLabels {
+		if errExpr != ig.errVar {
+			ig.p("\t\t%s = %s\n", ig.errVar, errExpr)
+		}
+		if ig.failLabels == nil {
+			ig.failLabels = make(map[int]bool)
+		}
+		ig.failLabels[prevCleanup] = true
+		ig.p("\t\tgoto %s\n", failLabel(prevCleanup))
+		ig.p("\t}\n")
+		return
+	}
+	if ig.panics {
 		for i := prevCleanup - 1; i >= 0; i-- {
 			ig.p("\t\t%s()\n", ig.cleanupNames[i])
 		}
-		ig.p("\t\treturn %s", zeroValue(injectSig.out, ig.g.qualifyPkg))
-		if injectSig.cleanup {
-			ig.p(", nil")
+		ig.p("\t\tpanic(%s)\n", errExpr)
+		ig.p("\t}\n")
+		return
+	}
+	if injectSig.cleanup && ig.g.opts.CleanupOnError {
+		// Leave the cleanups of the values built so far to the caller.
+		ig.p("\t\treturn %s, func() {", zeroValue(injectSig.out, ig.g.qualifyPkg))
+		if prevCleanup > 0 {
+			ig.p("\n")
+			for i := prevCleanup - 1; i >= 0; i-- {
+				ig.p("\t\t\t%s()\n", ig.cleanupNames[i])
+			}
+			ig.p("\t\t")
 		}
-		// TODO(light): Give information about failing provider.
-		ig.p(", err\n")
+		ig.p("}, %s\n", errExpr)
 		ig.p("\t}\n")
+		return
+	}
+	for i := prevCleanup - 1; i >= 0; i-- {
+		ig.p("\t\t%s()\n", ig.cleanupNames[i])
 	}
+	ig.p("\t\treturn %s", zeroValue(injectSig.out, ig.g.qualifyPkg))
+	if injectSig.cleanup {
+		ig.p(", nil")
+	}
+	// TODO(light): Give information about failing provider.
+	ig.p(", %s\n", errExpr)
+	ig.p("\t}\n")
 }
 
 func (ig *injectorGen) structProviderCall(lname string, c *call) {
 	ig.p("\t%s", lname)
-	ig.p(" := ")
+	ig.p(" %s ", ig.define())
 	if _, ok := c.out.(*types.Pointer); ok {
 		ig.p("&")
 	}
@@ -727,12 +2225,12 @@ func (ig *injectorGen) structProviderCall(lname string, c *call) {
 }
 
 func (ig *injectorGen) valueExpr(lname string, c *call) {
-	ig.p("\t%s := %s\n", lname, ig.g.values[c.valueExpr])
+	ig.p("\t%s %s %s\n", lname, ig.define(), ig.g.values[c.valueExpr])
 }
 
 func (ig *injectorGen) fieldExpr(lname string, c *call) {
 	a := c.args[0]
-	ig.p("\t%s := ", lname)
+	ig.p("\t%s %s ", lname, ig.define())
 	if c.ptrToField {
 		ig.p("&")
 	}
@@ -743,6 +2241,68 @@ func (ig *injectorGen) fieldExpr(lname string, c *call) {
 	}
 }
 
+func (ig *injectorGen) convertExpr(lname string, c *call) {
+	a := c.args[0]
+	ig.p("\t%s %s %s(", lname, ig.define(), types.TypeString(c.out, ig.g.qualifyPkg))
+	if a < len(ig.paramNames) {
+		ig.p("%s)\n", ig.paramNames[a])
+	} else {
+		ig.p("%s)\n", ig.localNames[a-len(ig.paramNames)])
+	}
+}
+
+func (ig *injectorGen) addressExpr(lname string, c *call) {
+	a := c.args[0]
+	if a < len(ig.paramNames) {
+		ig.p("\t%s %s &%s\n", lname, ig.define(), ig.paramNames[a])
+	} else {
+		ig.p("\t%s %s &%s\n", lname, ig.define(), ig.localNames[a-len(ig.paramNames)])
+	}
+}
+
+func (ig *injectorGen) derefExpr(lname string, c *call) {
+	ig.p("\t%s %s *%s\n", lname, ig.define(), ig.argName(c.args[0]))
+}
+
+// invokeCall writes the call of a function declared with wire.Invoke.
+func (ig *injectorGen) invokeCall(c *call, injectSig outputSignature) {
+	var args []string
+	for _, a := range c.args {
+		args = append(args, ig.argName(a))
+	}
+	expr := ig.g.qualifiedID(c.pkg.Name(), c.pkg.Path(), c.name) + "(" + strings.Join(args, ", ")
+	if c.varargs {
+		expr += "..."
+	}
+	expr += ")"
+	if !c.hasErr {
+		ig.p("\t%s\n", expr)
+		return
+	}
+	errVar := ig.errVar
+	if ig.g.opts.ErrorVarPerProvider {
+		errVar = disambiguate(providerErrVarName(c.name), ig.nameInInjector)
+		ig.errNames = append(ig.errNames, errVar)
+	}
+	ig.p("\tif %s %s %s; %s != nil {\n", errVar, ig.define(), expr, errVar)
+	ig.errReturn(errVar, c.name, len(ig.cleanupNames), injectSig)
+}
+
+func (ig *injectorGen) collectExpr(lname string, c *call) {
+	ig.p("\t%s %s %s{", lname, ig.define(), types.TypeString(c.out, ig.g.qualifyPkg))
+	for i, a := range c.args {
+		if i > 0 {
+			ig.p(", ")
+		}
+		if a < len(ig.paramNames) {
+			ig.p("%s", ig.paramNames[a])
+		} else {
+			ig.p("%s", ig.localNames[a-len(ig.paramNames)])
+		}
+	}
+	ig.p("}\n")
+}
+
 // nameInInjector reports whether name collides with any other identifier
 // in the current injector.
 func (ig *injectorGen) nameInInjector(name string) bool {
@@ -764,9 +2324,33 @@ func (ig *injectorGen) nameInInjector(name string) bool {
 			return true
 		}
 	}
+	for _, l := range ig.errNames {
+		if l == name {
+			return true
+		}
+	}
+	for _, l := range ig.resultNames {
+		if l == name {
+			return true
+		}
+	}
 	return ig.g.nameInFileScope(name)
 }
 
+// providerErrVarName returns the name of the error variable for a call to
+// the provider with the given name when ErrorVarPerProvider is set. A
+// leading "New" or "Provide" is dropped, so NewServer's error is errServer.
+func providerErrVarName(provider string) string {
+	name := provider
+	for _, prefix := range []string{"New", "new", "Provide", "provide"} {
+		if rest := strings.TrimPrefix(provider, prefix); rest != provider && rest != "" {
+			name = rest
+			break
+		}
+	}
+	return "err" + export(name)
+}
+
 func (ig *injectorGen) p(format string, args ...interface{}) {
 	if ig.discard {
 		return
@@ -799,6 +2383,17 @@ func zeroValue(t types.Type, qf types.Qualifier) string {
 	}
 }
 
+// typedZeroValue returns the shortest expression that evaluates to the zero
+// value of t and has type t.
+func typedZeroValue(t types.Type, qf types.Qualifier) string {
+	switch t.Underlying().(type) {
+	case *types.Array, *types.Struct:
+		return zeroValue(t, qf)
+	default:
+		return "(" + types.TypeString(t, qf) + ")(" + zeroValue(t, qf) + ")"
+	}
+}
+
 // typeVariableName invents a disambiguated variable name derived from the type name.
 // If no name can be derived from the type, defaultName is used.
 // transform is used to transform the derived name(s) (including defaultName);
@@ -807,8 +2402,10 @@ func zeroValue(t types.Type, qf types.Qualifier) string {
 // names is unambiguous, it used; otherwise, the first derived name is
 // disambiguated using disambiguate().
 func typeVariableName(t types.Type, defaultName string, transform func(string) string, collides func(string) bool) string {
+	// Name values after the type that an alias denotes.
+	t = types.Unalias(t)
 	if p, ok := t.(*types.Pointer); ok {
-		t = p.Elem()
+		t = types.Unalias(p.Elem())
 	}
 	var names []string
 	switch t := t.(type) {
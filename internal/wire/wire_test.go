@@ -17,9 +17,14 @@ package wire
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"go/ast"
 	"go/build"
+	"go/parser"
+	"go/token"
 	"go/types"
 	"io/ioutil"
 	"os"
@@ -88,7 +93,7 @@ func TestWire(t *testing.T) {
 				t.Fatal(err)
 			}
 			wd := filepath.Join(gopath, "src", "example.com")
-			gens, errs := Generate(ctx, wd, append(os.Environ(), "GOPATH="+gopath), []string{test.pkg}, &GenerateOptions{Header: test.header})
+			gens, errs := Generate(ctx, wd, append(os.Environ(), "GOPATH="+gopath), []string{test.pkg}, &test.opts)
 			var gen GenerateResult
 			if len(gens) > 1 {
 				t.Fatalf("got %d generated files, want 0 or 1", len(gens))
@@ -169,6 +174,10 @@ func goBuildCheck(goToolPath, gopath string, test *testCase) error {
 	// Run `go build`.
 	testExePath := filepath.Join(gopath, "bin", "testprog")
 	buildCmd := []string{"build", "-o", testExePath}
+	if test.opts.Tags != "" {
+		// Build with the tags the providers were selected with.
+		buildCmd = append(buildCmd, "-tags", test.opts.Tags)
+	}
 	buildCmd = append(buildCmd, test.pkg)
 	cmd := exec.Command(goToolPath, buildCmd...)
 	cmd.Dir = filepath.Join(gopath, "src", "example.com")
@@ -331,6 +340,645 @@ func TestDisambiguate(t *testing.T) {
 	}
 }
 
+func TestGenerateRejectsMultilineGeneratorName(t *testing.T) {
+	_, errs := Generate(context.Background(), ".", nil, []string{"."}, &GenerateOptions{GeneratorName: "Wire\npackage evil"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "line breaks") {
+		t.Errorf("Generate(...) errors = %v; want a single error about line breaks", errs)
+	}
+}
+
+func TestDocumentSets(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "ProviderDocs")
+	defer cleanup()
+	docs, errs := DocumentSets(context.Background(), wd, env, "", "", []string{"example.com/foo"})
+	for _, err := range errs {
+		t.Error(err)
+	}
+	type providerDoc struct {
+		Name, Signature, Doc string
+	}
+	var got []providerDoc
+	for _, sd := range docs {
+		if want := (ProviderSetID{ImportPath: "example.com/foo", VarName: "Set"}); sd.ID != want {
+			t.Errorf("set ID = %v; want %v", sd.ID, want)
+		}
+		for _, p := range sd.Providers {
+			got = append(got, providerDoc{p.ImportPath + "." + p.Name, p.Signature, p.Doc})
+		}
+	}
+	want := []providerDoc{
+		{"example.com/foo.provideGreeting", "func provideGreeting(name example.com/bar.Name) Greeting", "provideGreeting builds a greeting for the configured name.\n"},
+		{"example.com/bar.ProvideName", "func ProvideName() Name", "ProvideName returns the name to greet.\n"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("DocumentSets(...) diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestGenerateWarnings(t *testing.T) {
+	tests := []struct {
+		testCase string
+		opts     *GenerateOptions
+		want     []string
+	}{
+		{
+			testCase: "BuiltinTypes",
+			opts:     &GenerateOptions{WarnBuiltinTypes: true},
+			want: []string{
+				`example.com/foo/foo.go:x:y: inject injectClient: provider provideDSN provides string, which NewClient takes; declare a named type for the value, like "type DSN string", so that it cannot be confused with other string values`,
+			},
+		},
+		{
+			testCase: "ConvertibleArg",
+			want: []string{
+				"example.com/foo/foo.go:x:y: inject injectCommand: provider NewCommand takes example.com/foo.Args, which argument args of type []string is assignable to but not identical with, so the argument is not used for it",
+			},
+		},
+		{
+			testCase: "NoInjectors",
+			want: []string{
+				"example.com/foo/wire.go:x:y: no injectors found in files with the wireinject build tag",
+			},
+		},
+		{
+			testCase: "NoopBuild",
+		},
+		{
+			testCase: "ProviderDocsMissing",
+			opts:     &GenerateOptions{RequireProviderDocs: true},
+			want: []string{
+				"example.com/bar/bar.go:x:y: provider ProvideName has no doc comment",
+				"example.com/foo/foo.go:x:y: provider providePunctuation has no doc comment",
+			},
+		},
+		{
+			testCase: "UnusedInjectorArgs",
+			opts:     &GenerateOptions{WarnUnusedArgs: true},
+			want: []string{
+				"example.com/foo/wire.go:x:y: inject injectGreeting: argument retries is not used",
+				"example.com/foo/wire.go:x:y: inject injectName: argument verbose is not used",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testCase, func(t *testing.T) {
+			wd, env, cleanup := materializeTestCase(t, test.testCase)
+			defer cleanup()
+			gopath := filepath.Dir(filepath.Dir(wd))
+			gens, errs := Generate(context.Background(), wd, env, []string{"example.com/foo"}, test.opts)
+			for _, err := range errs {
+				t.Error(err)
+			}
+			if len(gens) != 1 {
+				t.Fatalf("got %d results; want 1", len(gens))
+			}
+			for _, err := range gens[0].Errs {
+				t.Error(err)
+			}
+			var got []string
+			for _, w := range gens[0].Warnings {
+				got = append(got, scrubError(gopath, w.Error()))
+			}
+			if diff := cmp.Diff(got, test.want); diff != "" {
+				t.Errorf("Generate(...) warnings diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateNoInjectors(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "NoInjectors")
+	defer cleanup()
+	gens, errs := Generate(context.Background(), wd, env, []string{"example.com/foo"}, nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(gens) != 1 {
+		t.Fatalf("got %d results; want 1", len(gens))
+	}
+	if len(gens[0].Content) > 0 {
+		t.Errorf("Content = %q; want empty", gens[0].Content)
+	}
+	if len(gens[0].Warnings) != 1 || !errors.Is(gens[0].Warnings[0], ErrNoInjectors) {
+		t.Errorf("Warnings = %v; want ErrNoInjectors", gens[0].Warnings)
+	}
+
+	// A generated file from an earlier run is kept if the files with the
+	// inject tag are left out of the build.
+	const stale = "// Code generated by Wire. DO NOT EDIT.\n\npackage main\n"
+	if err := ioutil.WriteFile(gens[0].OutputPath, []byte(stale), 0666); err != nil {
+		t.Fatal(err)
+	}
+	excluded, errs := Generate(context.Background(), wd, env, []string{"example.com/foo"}, &GenerateOptions{InjectTag: "otherinject"})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(excluded[0].Warnings) != 1 || !errors.Is(excluded[0].Warnings[0], ErrNoInjectors) {
+		t.Errorf("Warnings without inject files = %v; want ErrNoInjectors", excluded[0].Warnings)
+	}
+	if err := excluded[0].Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(gens[0].OutputPath); err != nil {
+		t.Errorf("after Commit without inject files: %v; want the generated file kept", err)
+	}
+	// It is removed if the files with the inject tag declare no injectors.
+	if err := gens[0].Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(gens[0].OutputPath); !os.IsNotExist(err) {
+		t.Errorf("after Commit with inject files: %v; want the generated file removed", err)
+	}
+}
+
+func TestGenerateDir(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "InjectInput")
+	defer cleanup()
+	ctx := context.Background()
+	want, errs := Generate(ctx, wd, env, []string{"example.com/foo"}, nil)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	got, errs := GenerateDir(ctx, filepath.Join(wd, "foo"), env, nil)
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if diff := cmp.Diff(got.Content, want[0].Content); diff != "" {
+		t.Errorf("GenerateDir(...) content diff (-got +want):\n%s", diff)
+	}
+	if got.PkgPath != "example.com/foo" || got.OutputPath != want[0].OutputPath {
+		t.Errorf("GenerateDir(...) = {PkgPath: %q, OutputPath: %q}; want {PkgPath: %q, OutputPath: %q}", got.PkgPath, got.OutputPath, "example.com/foo", want[0].OutputPath)
+	}
+
+	outside, err := ioutil.TempDir("", "wire_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+	if err := ioutil.WriteFile(filepath.Join(outside, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, errs := GenerateDir(ctx, outside, append(env, "GO111MODULE=off"), nil); len(errs) == 0 {
+		t.Error("GenerateDir outside of GOPATH succeeded; want error")
+	} else if !strings.Contains(errs[0].Error(), "not inside a module or GOPATH") {
+		t.Errorf("GenerateDir outside of GOPATH: %v; want error about the directory", errs[0])
+	}
+}
+
+func TestGenerateOutputPackage(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "ImportedInjector")
+	defer cleanup()
+	ctx := context.Background()
+	opts := &GenerateOptions{OutputPackage: "example.com/bar/wiring"}
+	gens, errs := Generate(ctx, wd, env, []string{"example.com/bar"}, opts)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(gens) != 1 {
+		t.Fatalf("got %d results; want 1", len(gens))
+	}
+	for _, err := range gens[0].Errs {
+		t.Error(err)
+	}
+	if want := filepath.Join(wd, "bar", "wiring", "wire_gen.go"); gens[0].OutputPath != want {
+		t.Errorf("OutputPath = %q; want %q", gens[0].OutputPath, want)
+	}
+	content := string(gens[0].Content)
+	for _, want := range []string{"package wiring\n", "\"example.com/bar\"", "func InitDB() (*bar.DB, func(), error) {", "dsn := bar.ProvideDSN()", "func DefaultDSN() bar.DSN {", "return bar.ProvideDSN()"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("generated file does not contain %q:\n%s", want, content)
+		}
+	}
+
+	// The providers of InjectInput are unexported.
+	wd, env, cleanup = materializeTestCase(t, "InjectInput")
+	defer cleanup()
+	opts = &GenerateOptions{OutputPackage: "example.com/foo/wiring"}
+	gens, errs = Generate(ctx, wd, env, []string{"example.com/foo"}, opts)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) == 0 {
+		t.Fatal("Generate with unexported providers succeeded; want error")
+	}
+	if got := gens[0].Errs[0].Error(); !strings.Contains(got, "example.com/foo.provideBar is not exported") {
+		t.Errorf("Generate with unexported providers: %s; want error about provideBar", got)
+	}
+
+	// injectConfig returns an unexported type.
+	wd, env, cleanup = materializeTestCase(t, "UnexportedInjectorType")
+	defer cleanup()
+	gens, errs = Generate(ctx, wd, env, []string{"example.com/foo"}, opts)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(gens) != 1 || len(gens[0].Errs) == 0 {
+		t.Fatal("Generate with an unexported injector type succeeded; want error")
+	}
+	if got := gens[0].Errs[0].Error(); !strings.Contains(got, "example.com/foo.config is not exported") {
+		t.Errorf("Generate with an unexported injector type: %s; want error about config", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	for _, name := range []string{"InjectInput", "MultipleMissingInputs"} {
+		t.Run(name, func(t *testing.T) {
+			wd, env, cleanup := materializeTestCase(t, name)
+			defer cleanup()
+			gopath := filepath.Dir(filepath.Dir(wd))
+			gens, errs := Generate(ctx, wd, env, []string{"example.com/foo"}, nil)
+			for _, gen := range gens {
+				errs = append(errs, gen.Errs...)
+			}
+			var want []string
+			for _, err := range errs {
+				want = append(want, scrubError(gopath, err.Error()))
+			}
+			var got []string
+			for _, err := range Check(ctx, wd, env, []string{"example.com/foo"}, nil) {
+				got = append(got, scrubError(gopath, err.Error()))
+			}
+			if diff := cmp.Diff(got, want); diff != "" {
+				t.Errorf("Check(...) errors diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckUnusedSet(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "UnusedSetConflict")
+	defer cleanup()
+	errs := Check(context.Background(), wd, env, []string{"example.com/foo"}, nil)
+	if len(errs) != 1 {
+		t.Fatalf("Check(...) = %v; want 1 error", errs)
+	}
+	if got := errs[0].Error(); !strings.Contains(got, "Set has multiple bindings for example.com/foo.Foo") {
+		t.Errorf("Check(...) error = %s; want error about Set", got)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "InjectInput")
+	defer cleanup()
+	got, errs := Graph(context.Background(), wd, env, "", "", []string{"example.com/foo"})
+	for _, err := range errs {
+		t.Error(err)
+	}
+	want := `digraph wire {
+	subgraph cluster_0 {
+		label="example.com/foo.injectFooBar";
+		"example.com/foo.injectFooBar#0" [label="argument foo\nexample.com/foo.Foo", shape=box];
+		"example.com/foo.injectFooBar#1" [label="example.com/foo.provideBar\nexample.com/foo.Bar"];
+		"example.com/foo.injectFooBar#2" [label="example.com/foo.provideFooBar\nexample.com/foo.FooBar"];
+		"example.com/foo.injectFooBar#2" -> "example.com/foo.injectFooBar#0";
+		"example.com/foo.injectFooBar#2" -> "example.com/foo.injectFooBar#1";
+	}
+}
+`
+	if diff := cmp.Diff(string(got), want); diff != "" {
+		t.Errorf("Graph(...) diff (-got +want):\n%s", diff)
+	}
+}
+
+func TestLoadInjectTag(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "InjectTag")
+	defer cleanup()
+	info, errs := Load(context.Background(), wd, env, "diinject", "", []string{"example.com/foo"})
+	for _, err := range errs {
+		t.Error(err)
+	}
+	if info == nil || len(info.Injectors) != 1 || info.Injectors[0].FuncName != "injectGreeting" {
+		t.Errorf("Load(...) = %+v; want the injectGreeting injector", info)
+	}
+	if _, errs := Load(context.Background(), wd, env, "", "", []string{"example.com/foo"}); len(errs) == 0 {
+		t.Error("Load(...) with the default inject tag succeeded; want errors from the files with the wireinject tag")
+	}
+}
+
+func TestSolve(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "Prefer")
+	defer cleanup()
+	got, errs := Solve(context.Background(), wd, env, "", "", "example.com/foo", []string{"example.com/foo.ProdSet"}, "*example.com/foo.Bar")
+	for _, err := range errs {
+		t.Error(err)
+	}
+	want := []CallInfo{
+		{Provider: "example.com/foo.NewProdFoo", Out: "*example.com/foo.Foo"},
+		{Provider: "example.com/foo.NewBar", Out: "*example.com/foo.Bar", Args: []int{0}},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Solve(...) diff (-got +want):\n%s", diff)
+	}
+
+	_, errs = Solve(context.Background(), wd, env, "", "", "example.com/foo", []string{"DevSet"}, "string")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "no provider found for string") {
+		t.Errorf("Solve(...) for string = _, %v; want an error about the missing provider", errs)
+	}
+	_, errs = Solve(context.Background(), wd, env, "", "", "example.com/foo", []string{"NoSuchSet"}, "*example.com/foo.Missing")
+	if len(errs) != 2 {
+		t.Errorf("Solve(...) with an unknown set and type = _, %v; want 2 errors", errs)
+	}
+}
+
+func TestObjectCacheForPackage(t *testing.T) {
+	wd, env, cleanup := materializeTestCase(t, "ImportedInjector")
+	defer cleanup()
+	pkgs, errs := load(context.Background(), wd, env, defaultInjectTag, "", []string{"example.com/foo", "example.com/bar"})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	oc := newObjectCache(pkgs)
+	var got []interface{}
+	for _, pkg := range pkgs {
+		c := oc.forPackage(pkg)
+		bar := c.packages["example.com/bar"]
+		if bar == nil {
+			t.Fatalf("%s: example.com/bar not found", pkg.PkgPath)
+		}
+		item, errs := c.get(bar.Types.Scope().Lookup("ProvideDSN"))
+		if len(errs) > 0 {
+			t.Fatal(errs)
+		}
+		got = append(got, item)
+	}
+	if got[0] != got[1] {
+		t.Error("ProvideDSN was processed again for the second package; want the first result reused")
+	}
+}
+
+func TestDirectiveValue(t *testing.T) {
+	const src = `package foo
+
+// injectServer builds a server.
+//
+//wire:must
+//wire:name NewServer
+//wire:validate checkServer
+func injectServer() {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := f.Decls[0].(*ast.FuncDecl).Doc
+	tests := []struct {
+		directive string
+		want      string
+		wantLine  int
+	}{
+		{directive: "wire:name", want: "NewServer", wantLine: 6},
+		{directive: "wire:validate", want: "checkServer", wantLine: 7},
+	}
+	for _, test := range tests {
+		got, pos, ok := directiveValue(doc, test.directive)
+		if !ok || got != test.want {
+			t.Errorf("directiveValue(doc, %q) = %q, _, %t; want %q, _, true", test.directive, got, ok, test.want)
+		}
+		if line := fset.Position(pos).Line; line != test.wantLine {
+			t.Errorf("directiveValue(doc, %q) position is on line %d; want %d", test.directive, line, test.wantLine)
+		}
+	}
+	if _, _, ok := directiveValue(doc, "wire:ignore"); ok {
+		t.Error("directiveValue(doc, \"wire:ignore\") found a directive; want none")
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+	got, err := formatSource([]byte("package foo\nfunc  f( ) {}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "package foo\n\nfunc f() {}\n"; string(got) != want {
+		t.Errorf("formatSource(...) = %q; want %q", got, want)
+	}
+	_, err = formatSource([]byte("package foo\nfunc f( {}\n"))
+	var fe *FormatError
+	if !errors.As(err, &fe) {
+		t.Fatalf("formatSource(invalid) error = %v; want a *FormatError", err)
+	}
+	if fe.Err == nil || !strings.Contains(err.Error(), "bug in Wire") {
+		t.Errorf("formatSource(invalid) error = %v; want it to wrap the format error and report a Wire bug", err)
+	}
+}
+
+func TestCommitReadOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wire_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	gen := GenerateResult{
+		OutputPath: filepath.Join(dir, "wire_gen.go"),
+		Content:    []byte("// Code generated by Wire. DO NOT EDIT.\n\npackage foo\n"),
+		Mode:       0444,
+	}
+	// The second commit replaces the read-only file from the first.
+	for i := 0; i < 2; i++ {
+		if err := gen.Commit(); err != nil {
+			t.Fatalf("Commit #%d: %v", i+1, err)
+		}
+		fi, err := os.Stat(gen.OutputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fi.Mode().Perm(); got != 0444 {
+			t.Errorf("after Commit #%d, mode = %v; want %v", i+1, got, os.FileMode(0444))
+		}
+		gen.Content = []byte("// Code generated by Wire. DO NOT EDIT.\n\npackage bar\n")
+	}
+	got, err := ioutil.ReadFile(gen.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// Code generated by Wire. DO NOT EDIT.\n\npackage bar\n"; string(got) != want {
+		t.Errorf("content = %q; want %q", got, want)
+	}
+}
+
+func TestCommitExistingFile(t *testing.T) {
+	const (
+		handWritten = "package foo\n\n// Code generated by hand. DO NOT EDIT.\n"
+		generated   = "// Header.\n\n// Code generated by Wire. DO NOT EDIT.\n\npackage foo\n"
+	)
+	tests := []struct {
+		name     string
+		existing string
+		content  string
+		errs     []error
+		// removeStale is true if the files with the inject tag declare no
+		// injectors.
+		removeStale bool
+		wantErr     bool
+		// want is the content of the file after Commit, or empty if the
+		// file must not exist.
+		want string
+	}{
+		{name: "ReplaceGenerated", existing: generated, content: "package bar\n", want: "package bar\n"},
+		{name: "KeepHandWritten", existing: handWritten, content: "package bar\n", wantErr: true, want: handWritten},
+		{name: "RemoveGenerated", existing: generated, removeStale: true},
+		{name: "KeepGeneratedWithoutInjectFiles", existing: generated, want: generated},
+		{name: "KeepGeneratedOnErrors", existing: generated, errs: []error{errors.New("failed")}, removeStale: true, want: generated},
+		{name: "KeepHandWrittenWithoutOutput", existing: handWritten, want: handWritten},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "wire_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			gen := GenerateResult{
+				OutputPath:  filepath.Join(dir, "wire_gen.go"),
+				Content:     []byte(test.content),
+				Errs:        test.errs,
+				removeStale: test.removeStale,
+			}
+			if err := ioutil.WriteFile(gen.OutputPath, []byte(test.existing), 0666); err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.Commit(); (err != nil) != test.wantErr {
+				t.Errorf("Commit() = %v; want error: %t", err, test.wantErr)
+			}
+			got, err := ioutil.ReadFile(gen.OutputPath)
+			if test.want == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("after Commit, file exists with error %v; want it removed", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("after Commit, content = %q; want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	got, err := ParseConfig([]byte(`{
+		"injectors": {
+			"example.com/foo.injectB": ["example.com/foo/bar.Set"],
+			"example.com/foo.injectA": ["example.com/foo.Set", "example.com/baz.Other"]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []InjectorSets{
+		{
+			Injector: Injector{ImportPath: "example.com/foo", FuncName: "injectA"},
+			Sets: []ProviderSetID{
+				{ImportPath: "example.com/foo", VarName: "Set"},
+				{ImportPath: "example.com/baz", VarName: "Other"},
+			},
+		},
+		{
+			Injector: Injector{ImportPath: "example.com/foo", FuncName: "injectB"},
+			Sets: []ProviderSetID{
+				{ImportPath: "example.com/foo/bar", VarName: "Set"},
+			},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("ParseConfig(...) diff (-got +want):\n%s", diff)
+	}
+
+	for _, bad := range []string{
+		`{"injectors": {"injectA": ["example.com/foo.Set"]}}`,
+		`{"injectors": {"example.com/foo.injectA": ["example.com/foo"]}}`,
+		`{"injectors": {"example.com/foo.injectA": ["example.com/foo.My-Set"]}}`,
+		`{"injectors": {"example.com/foo.inject A": ["example.com/foo.Set"]}}`,
+		`{"injectors": []}`,
+	} {
+		if _, err := ParseConfig([]byte(bad)); err == nil {
+			t.Errorf("ParseConfig(%s) succeeded; want error", bad)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		testCase string
+		want     InjectorMetric
+	}{
+		{
+			testCase: "PartialCleanup",
+			want: InjectorMetric{
+				ImportPath: "example.com/foo",
+				FuncName:   "injectBaz",
+				Providers:  3,
+				MaxDepth:   3,
+				Packages:   1,
+				CanFail:    true,
+				HasCleanup: true,
+			},
+		},
+		{
+			testCase: "ProviderDocs",
+			want: InjectorMetric{
+				ImportPath: "example.com/foo",
+				FuncName:   "injectGreeting",
+				Providers:  2,
+				MaxDepth:   2,
+				Packages:   2,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.testCase, func(t *testing.T) {
+			wd, env, cleanup := materializeTestCase(t, test.testCase)
+			defer cleanup()
+			metrics, errs := Metrics(context.Background(), wd, env, "", "", []string{"example.com/foo"})
+			for _, err := range errs {
+				t.Error(err)
+			}
+			if len(metrics) != 1 {
+				t.Fatalf("got %d metrics; want 1", len(metrics))
+			}
+			got := metrics[0]
+			got.Pos = token.Position{}
+			if diff := cmp.Diff(got, test.want); diff != "" {
+				t.Errorf("Metrics(...) diff (-got +want):\n%s", diff)
+			}
+		})
+	}
+}
+
+// materializeTestCase writes the test case in testdata/name into a new
+// temporary GOPATH. It returns the working directory and environment to load
+// the test case's packages with, along with a function that removes the
+// GOPATH.
+func materializeTestCase(t *testing.T, name string) (wd string, env []string, cleanup func()) {
+	t.Helper()
+	wireGo, err := ioutil.ReadFile(filepath.Join("..", "..", "wire.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test, err := loadTestCase(filepath.Join("testdata", name), wireGo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopath, err := ioutil.TempDir("", "wire_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup = func() { os.RemoveAll(gopath) }
+	gopath, err = filepath.EvalSymlinks(gopath)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	if err := test.materialize(gopath); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	return filepath.Join(gopath, "src", "example.com"), append(os.Environ(), "GOPATH="+gopath), cleanup
+}
+
 func isIdent(s string) bool {
 	if len(s) == 0 {
 		return false
@@ -427,6 +1075,7 @@ type testCase struct {
 	name                 string
 	pkg                  string
 	header               []byte
+	opts                 GenerateOptions
 	goFiles              map[string][]byte
 	wantProgramOutput    []byte
 	wantWireOutput       []byte
@@ -444,6 +1093,10 @@ type testCase struct {
 //			file containing the package name containing the inject function
 //			(must also be package main)
 //
+//		options.json
+//			optional JSON encoding of the GenerateOptions to use, excluding
+//			the header
+//
 //		...
 //			any Go files found recursively placed under GOPATH/src/...
 //
@@ -470,6 +1123,13 @@ func loadTestCase(root string, wireGoSrc []byte) (*testCase, error) {
 		return nil, fmt.Errorf("load test case %s: %v", name, err)
 	}
 	header, _ := ioutil.ReadFile(filepath.Join(root, "header"))
+	var opts GenerateOptions
+	if optsJSON, err := ioutil.ReadFile(filepath.Join(root, "options.json")); err == nil {
+		if err := json.Unmarshal(optsJSON, &opts); err != nil {
+			return nil, fmt.Errorf("load test case %s: options.json: %v", name, err)
+		}
+	}
+	opts.Header = header
 	var wantProgramOutput []byte
 	var wantWireOutput []byte
 	wireErrb, err := ioutil.ReadFile(filepath.Join(root, "want", "wire_errs.txt"))
@@ -521,6 +1181,7 @@ func loadTestCase(root string, wireGoSrc []byte) (*testCase, error) {
 		name:                 name,
 		pkg:                  string(bytes.TrimSpace(pkg)),
 		header:               header,
+		opts:                 opts,
 		goFiles:              goFiles,
 		wantWireOutput:       wantWireOutput,
 		wantProgramOutput:    wantProgramOutput,
@@ -90,6 +90,155 @@ func Build(...interface{}) string {
 	return "implementation not generated, run wire"
 }
 
+// A Collection gathers the results of several providers into a slice.
+type Collection struct{}
+
+// Collect declares that a slice type is provided by calling each of the given
+// providers and collecting their results in order. The first argument must be
+// a pointer to the slice type, and each provider's output must be assignable
+// to the slice's element type. The providers do not provide their own output
+// types to the rest of the set. Collections of the same slice type in a set
+// and the sets it includes are merged into one, so that each set can
+// contribute its own elements.
+//
+// A provider with a variadic parameter of the element type can then assemble
+// the collected values:
+//
+//	func NewMux(routes ...Route) *http.ServeMux { /* ... */ }
+//
+//	var Set = wire.NewSet(
+//		wire.Collect(new([]Route), NewHomeRoute, NewAPIRoute),
+//		NewMux)
+func Collect(sliceType interface{}, providers ...interface{}) Collection {
+	return Collection{}
+}
+
+// A Preference selects one provider among several for the same type.
+type Preference struct{}
+
+// Prefer resolves a conflict between providers of the same type in favor of
+// the given provider function, dropping the others for the injector. It may
+// only be used in a call to Build, and it is an error if the provider does
+// not take part in any conflict.
+//
+// Example:
+//
+//	func injectFoo() *Foo {
+//		wire.Build(SetA, SetB, wire.Prefer(NewFoo))
+//		return nil
+//	}
+func Prefer(provider interface{}) Preference {
+	return Preference{}
+}
+
+// A Replacement swaps a provider into an injector's provider set.
+type Replacement struct{}
+
+// Override adds the given provider function to the injector, replacing any
+// other provider of its output types, including those of the sets passed to
+// Build. Unlike Prefer, the provider does not also need to be listed. It may
+// only be used in a call to Build, and is useful for swapping a fake into an
+// existing set in tests.
+//
+// Example:
+//
+//	func injectCheckout() *Checkout {
+//		wire.Build(ProductionSet, wire.Override(NewStubPaymentGateway))
+//		return nil
+//	}
+func Override(provider interface{}) Replacement {
+	return Replacement{}
+}
+
+// A GenericProvider is a generic provider function whose type arguments
+// are inferred.
+type GenericProvider struct{}
+
+// Generic declares that the generic provider function instantiated by fn
+// is used for any type arguments. Since Go requires a generic function
+// value to be instantiated, fn names the function with type arguments,
+// but Wire ignores them: for each type that an injector needs and that
+// nothing else provides, Wire infers the type arguments from the function's
+// output types and calls the function with them. It is an error if more
+// than one generic provider can provide a type.
+//
+// Example:
+//
+//	func NewCache[T any]() *Cache[T] { /* ... */ }
+//
+//	// Provides *Cache[string], *Cache[int], and so on.
+//	var CacheSet = wire.NewSet(wire.Generic(NewCache[any]))
+func Generic(fn interface{}) GenericProvider {
+	return GenericProvider{}
+}
+
+// Fallback combines provider sets in order of precedence. Each type is
+// provided by the first set that provides it, so later sets only fill in
+// the types that earlier sets lack. This differs from NewSet, which reports
+// an error when two of its arguments provide the same type.
+//
+// Example:
+//
+//	var Set = wire.Fallback(ProductionSet, DefaultsSet)
+func Fallback(sets ...ProviderSet) ProviderSet {
+	return ProviderSet{}
+}
+
+// PackageSets stands for all of the package-level provider sets declared in
+// the package of the injector, as if each were passed to Build. It may only
+// be used in a call to Build, and it is an error if the sets provide the
+// same type more than once.
+//
+// Example:
+//
+//	func injectFoo() *Foo {
+//		wire.Build(wire.PackageSets())
+//		return nil
+//	}
+func PackageSets() ProviderSet {
+	return ProviderSet{}
+}
+
+// An Invocation is a function that an injector calls for its side effects.
+type Invocation struct{}
+
+// Invoke declares that the injector calls fn, a function that returns
+// nothing or only an error, before it builds its output. The arguments of fn
+// are built from the provider set like those of any provider, but nothing
+// can depend on the call, so it may only be used in a call to Build. The
+// functions are called in the order they are listed, and the injector must
+// return an error if any of them can fail.
+//
+// Example:
+//
+//	func RegisterMetrics(r *prometheus.Registry, db *DB) { /* ... */ }
+//
+//	func injectServer() (*Server, error) {
+//		wire.Build(ServerSet, wire.Invoke(RegisterMetrics))
+//		return nil, nil
+//	}
+func Invoke(fn interface{}) Invocation {
+	return Invocation{}
+}
+
+// A Sharing marks a type whose value is shared by injectors.
+type Sharing struct{}
+
+// Shared declares that the value of the type that ptr points to is built at
+// most once and shared by all of the injectors in the generated file that
+// need it, instead of each call of an injector building its own. The
+// argument should be a pointer to the type, as in new(*DB). Building the
+// value must not depend on injector arguments or return a cleanup function.
+// If it fails, every later call of the injectors that need it returns the
+// same error.
+//
+// Example:
+//
+//	var DBSet = wire.NewSet(NewDB, wire.Shared(new(*DB)))
+func Shared(ptr interface{}) Sharing {
+	return Sharing{}
+}
+
 // A Binding maps an interface to a concrete type.
 type Binding struct{}
 